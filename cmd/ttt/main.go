@@ -1,9 +1,126 @@
 package main
 
-import "ttt/internal/game"
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+
+	"ttt/internal/game"
+)
 
 func main() {
-	g := game.NewGame()
-	g.Initialize()
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags]\n       %s replay <file>\n", os.Args[0], os.Args[0])
+		flag.PrintDefaults()
+	}
+	load := flag.String("load", "", "resume a game saved with the in-game \"save [file]\" command")
+	record := flag.String("log", "", "record the game's events to a file for \"replay\"")
+	profile := flag.Bool("profile", false, "print how long each system took when the game ends")
+	logFile := flag.String("log-file", "", "write diagnostic logs to a file instead of stderr")
+	logLevel := flag.String("log-level", "warn", "minimum level of diagnostic logs: debug, info, warn or error")
+	logFormat := flag.String("log-format", "text", "format of diagnostic logs: text or json")
+	flag.Parse()
+
+	logger, closeLog, err := newLogger(*logFile, *logLevel, *logFormat)
+	if err != nil {
+		log.Fatalf("Failed to set up logging: %v", err)
+	}
+	defer closeLog()
+
+	if flag.Arg(0) == "replay" {
+		if flag.NArg() != 2 {
+			flag.Usage()
+			os.Exit(2)
+		}
+		replay(logger, flag.Arg(1), *profile)
+		return
+	}
+	if flag.NArg() > 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *load != "" && *record != "" {
+		log.Fatal("--log can only record new games, not ones resumed with --load")
+	}
+
+	g := game.NewGame(logger)
+	if *load != "" {
+		if err := g.Load(*load); err != nil {
+			log.Fatalf("Failed to load game: %v", err)
+		}
+	} else if err := g.Initialize(); err != nil {
+		log.Fatalf("Failed to initialize game: %v", err)
+	}
+
+	if *record != "" {
+		f, err := os.Create(*record)
+		if err != nil {
+			log.Fatalf("Failed to create event log: %v", err)
+		}
+		defer f.Close()
+		g.Record(f)
+	}
+	if *profile {
+		g.EnableProfiling()
+	}
 	g.Run()
+	if *profile {
+		writeProfile(g)
+	}
+}
+
+// replay checks a new game against an event log recorded with --log
+func replay(logger *slog.Logger, path string, profile bool) {
+	g := game.NewGame(logger)
+	if err := g.Initialize(); err != nil {
+		log.Fatalf("Failed to initialize game: %v", err)
+	}
+	if profile {
+		g.EnableProfiling()
+	}
+	if err := g.Replay(path); err != nil {
+		log.Fatalf("Replay failed: %v", err)
+	}
+	if profile {
+		writeProfile(g)
+	}
+}
+
+func writeProfile(g *game.Game) {
+	if err := g.WriteProfile(os.Stderr); err != nil {
+		log.Printf("Failed to write profile: %v", err)
+	}
+}
+
+// newLogger builds the diagnostic logger from the command line flags. The
+// returned function closes the log file, if any.
+func newLogger(path, level, format string) (*slog.Logger, func() error, error) {
+	var opts slog.HandlerOptions
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return nil, nil, err
+	}
+	opts.Level = lvl
+
+	var out io.Writer = os.Stderr
+	closeLog := func() error { return nil }
+	if path != "" {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, nil, err
+		}
+		out, closeLog = f, f.Close
+	}
+
+	switch format {
+	case "text":
+		return slog.New(slog.NewTextHandler(out, &opts)), closeLog, nil
+	case "json":
+		return slog.New(slog.NewJSONHandler(out, &opts)), closeLog, nil
+	}
+	closeLog()
+	return nil, nil, fmt.Errorf("unknown log format %q", format)
 }
@@ -4,4 +4,5 @@ type DisplayManager interface {
 	ShowBoard(board [][]string)
 	ShowTurnPrompt(player string)
 	ShowGameResult(result string)
+	ShowMessage(message string)
 }
@@ -2,24 +2,6 @@ package components
 
 import "ttt/pkg/ecs"
 
-const (
-	GameState  ecs.ComponentType = "game_state"
-	Board      ecs.ComponentType = "board"
-	Player     ecs.ComponentType = "player"
-	MoveIntent ecs.ComponentType = "move_intent"
-)
-
-type GameStateComponent struct {
-	ecs.Component
-	PlayerTurn ecs.Entity
-	GameOver   bool
-}
-
-func (c GameStateComponent) IsComponent() {}
-func (c GameStateComponent) GetType() ecs.ComponentType {
-	return GameState
-}
-
 type CellState int
 
 const (
@@ -28,39 +10,34 @@ const (
 	Player2
 )
 
-type BoardComponent struct {
-	Board [][]CellState
-}
-
-func (c BoardComponent) IsComponent() {}
-func (c BoardComponent) GetType() ecs.ComponentType {
-	return Board
-}
-
 type PlayerComponent struct {
-	ecs.Component
 	Character string
 	CellState CellState
 }
 
-func (c PlayerComponent) IsComponent() {}
-func (c PlayerComponent) GetType() ecs.ComponentType {
-	return Player
-}
-
 type MoveIntentComponent struct {
-	ecs.Component
 	Row int
 	Col int
 }
 
-func (c MoveIntentComponent) IsComponent() {}
-func (c MoveIntentComponent) GetType() ecs.ComponentType {
-	return MoveIntent
-}
+// CurrentTurn tags the player whose turn it is
+type CurrentTurn struct{}
+
+// Human tags players controlled from the console
+type Human struct{}
+
+// Bot tags players controlled by the computer
+type Bot struct{}
+
+// Winner tags the player who won the game
+type Winner struct{}
 
-var ComponentTypes = []ecs.ComponentType{
-	Board,
-	Player,
-	MoveIntent,
+func init() {
+	// Names identify the components in saved games
+	ecs.RegisterComponent[PlayerComponent]("player")
+	ecs.RegisterComponent[MoveIntentComponent]("move_intent")
+	ecs.RegisterComponent[CurrentTurn]("current_turn")
+	ecs.RegisterComponent[Human]("human")
+	ecs.RegisterComponent[Bot]("bot")
+	ecs.RegisterComponent[Winner]("winner")
 }
@@ -2,41 +2,61 @@ package game
 
 import (
 	"ttt/internal/game/components"
+	"ttt/internal/game/events"
+	"ttt/internal/game/resources"
 	"ttt/pkg/ecs"
 )
 
-func (g *Game) playerMovedEventHandler(event ecs.EventInterface) {
-	gameState := g.getGameState()
-	if gameState == nil {
-		return
+func (g *Game) moveRequestedEventHandler(event events.MoveRequestedEvent) {
+	// Add a move intent component to the player entity
+	err := ecs.Add(g.world, event.Ent, components.MoveIntentComponent{
+		Row: event.Row,
+		Col: event.Col,
+	})
+	if err != nil {
+		g.world.Logger.Error("failed to queue move", "entity", g.world.Label(event.Ent), "error", err)
 	}
+}
+
+func (g *Game) playerMovedEventHandler(event events.PlayerMovedEvent) {
+	g.world.Logger.Debug("player moved", "entity", g.world.Label(event.Ent), "row", event.Row, "col", event.Col)
 
-	// Toggle the turn
-	playerEnts := g.world.ComponentManager.GetAllEntitiesWithComponent(components.Player)
-	if len(playerEnts) != 2 {
+	// Pass the turn to the other player
+	other := playerO
+	if g.world.NameOf(event.Ent) == playerO {
+		other = playerX
+	}
+	otherEnt, exists := g.world.Lookup(other)
+	if !exists {
+		g.world.Logger.Error("cannot switch turns, player is missing", "player", other)
 		return
 	}
-
-	if gameState.PlayerTurn == playerEnts[0] {
-		gameState.PlayerTurn = playerEnts[1]
-	} else {
-		gameState.PlayerTurn = playerEnts[0]
+	if err := ecs.MoveTag[components.CurrentTurn](g.world, event.Ent, otherEnt); err != nil {
+		g.world.Logger.Error("cannot switch turns", "entity", g.world.Label(event.Ent), "error", err)
 	}
 }
 
-func (g *Game) playerWonEventHandler(event ecs.EventInterface) {
-	gameState := g.getGameState()
-	if gameState != nil {
+func (g *Game) playerWonEventHandler(event events.PlayerWonEvent) {
+	gameState, err := ecs.ResourceMut[resources.GameState](g.world)
+	if err != nil {
+		g.world.Logger.Error("cannot end game", "event", event.Type(), "error", err)
+	} else {
 		gameState.GameOver = true
 	}
 
-	player, _ := g.componentAccess.GetPlayerComponent(event.Entity())
+	if err := ecs.AddTag[components.Winner](g.world, event.Ent); err != nil {
+		g.world.Logger.Error("cannot mark winner", "entity", g.world.Label(event.Ent), "error", err)
+	}
+
+	player, _ := ecs.Get[components.PlayerComponent](g.world, event.Ent)
 	g.displayManager.ShowGameResult(player.Character + " won!")
 }
 
-func (g *Game) tieEventHandler(event ecs.EventInterface) {
-	gameState := g.getGameState()
-	if gameState != nil {
+func (g *Game) tieEventHandler(event events.TieEvent) {
+	gameState, err := ecs.ResourceMut[resources.GameState](g.world)
+	if err != nil {
+		g.world.Logger.Error("cannot end game", "event", event.Type(), "error", err)
+	} else {
 		gameState.GameOver = true
 	}
 
@@ -3,11 +3,30 @@ package events
 import "ttt/pkg/ecs"
 
 const (
-	PlayerMoved ecs.EventType = "player_moved"
-	PlayerWon   ecs.EventType = "player_won"
-	Tie         ecs.EventType = "tie"
+	MoveRequested ecs.EventType = "move_requested"
+	PlayerMoved   ecs.EventType = "player_moved"
+	PlayerWon     ecs.EventType = "player_won"
+	Tie           ecs.EventType = "tie"
 )
 
+// MoveRequestedEvent is a player's input, before it has been checked
+type MoveRequestedEvent struct {
+	Ent      ecs.Entity
+	Row, Col int
+}
+
+func (e MoveRequestedEvent) Type() ecs.EventType {
+	return MoveRequested
+}
+
+func (e MoveRequestedEvent) Entity() ecs.Entity {
+	return e.Ent
+}
+
+func (e MoveRequestedEvent) Data() any {
+	return map[string]int{"row": e.Row, "col": e.Col}
+}
+
 type PlayerMovedEvent struct {
 	Ent      ecs.Entity
 	Row, Col int
@@ -56,3 +75,11 @@ func (e TieEvent) Entity() ecs.Entity {
 func (e TieEvent) Data() any {
 	return nil
 }
+
+func init() {
+	// Registered so event logs can be replayed
+	ecs.RegisterEvent[MoveRequestedEvent]()
+	ecs.RegisterEvent[PlayerMovedEvent]()
+	ecs.RegisterEvent[PlayerWonEvent]()
+	ecs.RegisterEvent[TieEvent]()
+}
@@ -1,79 +1,131 @@
 package game
 
 import (
-	"log"
-	"os"
+	"bytes"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
 
 	"ttt/internal/game/components"
 	"ttt/internal/game/events"
+	"ttt/internal/game/resources"
 	"ttt/internal/game/systems"
 	"ttt/internal/game/ui/console"
+	"ttt/internal/input"
 	"ttt/pkg/ecs"
 )
 
 type Game struct {
-	world           *ecs.World
-	inputManager    console.ConsoleInputManager
-	displayManager  console.ConsoleDisplayManager
-	componentAccess *components.ComponentAccess
+	world          *ecs.World
+	runner         *ecs.Runner
+	inputManager   console.ConsoleInputManager
+	displayManager console.ConsoleDisplayManager
+	boardShown     ecs.Tick // when the board was last displayed
+	currentPlayer  *ecs.Query1[components.PlayerComponent]
 }
 
-func NewGame() *Game {
-	logger := log.New(os.Stdout, "TicTacToe: ", log.LstdFlags)
+// Player entities are named after the prefab they are made from
+const (
+	playerX = "player_x"
+	playerO = "player_o"
+)
 
+// NewGame creates a game logging to logger. Logs are for diagnostics only,
+// everything meant for the players goes through the display.
+func NewGame(logger *slog.Logger) *Game {
 	world := ecs.NewWorld(logger)
-
-	// Create the component access manager
-	componentAccess := components.NewComponentAccess(world)
-
-	// Register core ECS systems
-	world.AddSystem(&systems.MoveSystem{
-		ComponentAccess: componentAccess,
-	})
-	world.AddSystem(&systems.BoardSystem{
-		ComponentAccess: componentAccess,
-	})
+	// Errors are handled by the game loop, see Run
+	world.SetErrorPolicy(ecs.ReturnErrors)
+
+	// Register core ECS systems. Moves are applied when a player makes
+	// one, before the board is checked for a result, and nothing runs once
+	// the game is over.
+	world.AddSystem(
+		systems.NewMoveSystem(),
+		ecs.Name("move"),
+		ecs.RunIf(gameInProgress),
+		ecs.RunOnEnter(ecs.NewQuery(ecs.All(ecs.ID[components.MoveIntentComponent]()))),
+	)
+	world.AddSystem(
+		systems.NewBoardSystem(),
+		ecs.Name("board"),
+		ecs.After("move"),
+		ecs.RunIf(gameInProgress),
+		ecs.Reads(
+			ecs.ID[resources.Board](),
+			ecs.ID[components.PlayerComponent](),
+		),
+	)
 
 	return &Game{
-		world:           world,
-		inputManager:    console.NewConsoleInputManager(),
-		displayManager:  console.NewConsoleDisplayManager(),
-		componentAccess: componentAccess,
+		world:          world,
+		runner:         ecs.NewRunner(world),
+		inputManager:   console.NewConsoleInputManager(),
+		displayManager: console.NewConsoleDisplayManager(),
+		currentPlayer: ecs.NewQuery1[components.PlayerComponent](
+			ecs.With[components.CurrentTurn](),
+		),
 	}
 }
 
-func (g *Game) Initialize() {
-	// Register component types
-	g.registerComponentTypes()
+// Initialize sets up a new game
+func (g *Game) Initialize() error {
+	if err := g.setup(); err != nil {
+		return err
+	}
+	return g.createEntities()
+}
+
+// setup prepares everything that is not part of the game state
+func (g *Game) setup() error {
+	// Validate system ordering before anything runs
+	if err := g.world.BuildSchedule(); err != nil {
+		return err
+	}
 
 	// Register event handlers
-	g.world.RegisterEventHandler(events.PlayerMoved, g.playerMovedEventHandler)
-	g.world.RegisterEventHandler(events.PlayerWon, g.playerWonEventHandler)
-	g.world.RegisterEventHandler(events.Tie, g.tieEventHandler)
-
-	// Make the player 1 entity
-	player1 := g.world.EntityManager.CreateEntity()
-	g.world.ComponentManager.AddComponent(
-		player1,
-		components.Player,
-		&components.PlayerComponent{
-			Character: "X",
-			CellState: components.Player1,
-		},
-	)
+	ecs.Subscribe(g.world, g.moveRequestedEventHandler)
+	ecs.Subscribe(g.world, g.playerMovedEventHandler)
+	ecs.Subscribe(g.world, g.playerWonEventHandler)
+	ecs.Subscribe(g.world, g.tieEventHandler)
+	return nil
+}
 
-	// Make the player 2 entity
-	player2 := g.world.EntityManager.CreateEntity()
-	g.world.ComponentManager.AddComponent(
-		player2,
-		components.Player,
-		&components.PlayerComponent{
-			Character: "O",
-			CellState: components.Player2,
-		},
-	)
+// prefabsJSON declares the entities a new game is made of
+//
+//go:embed prefabs.json
+var prefabsJSON []byte
+
+func (g *Game) createEntities() error {
+	prefabs, err := ecs.LoadPrefabs(bytes.NewReader(prefabsJSON))
+	if err != nil {
+		return err
+	}
+
+	// Make the player entities, named after their prefab. Player 1 starts.
+	for _, player := range []struct {
+		prefab    string
+		overrides []ecs.PrefabComponent
+	}{
+		{playerX, []ecs.PrefabComponent{ecs.Component(components.CurrentTurn{})}},
+		{playerO, nil},
+	} {
+		prefab, exists := prefabs[player.prefab]
+		if !exists {
+			return fmt.Errorf("missing prefab %q", player.prefab)
+		}
+		playerEnt, err := prefab.Instantiate(g.world, player.overrides...)
+		if err != nil {
+			return err
+		}
+		if err := g.world.SetName(playerEnt, player.prefab); err != nil {
+			return err
+		}
+	}
 
-	// Make the board entity
+	// Make the board
 	boardTiles := make([][]components.CellState, 3)
 	for i := range boardTiles {
 		boardTiles[i] = make([]components.CellState, 3)
@@ -82,94 +134,101 @@ func (g *Game) Initialize() {
 		}
 	}
 
-	board := g.world.EntityManager.CreateEntity()
-	g.world.ComponentManager.AddComponent(
-		board,
-		components.Board,
-		&components.BoardComponent{
-			Board: boardTiles,
-		},
-	)
-
-	// Make the game state entity
-	gameState := g.world.EntityManager.CreateEntity()
-	g.world.ComponentManager.AddComponent(
-		gameState,
-		components.GameState,
-		&components.GameStateComponent{
-			PlayerTurn: player1,
-			GameOver:   false,
-		},
-	)
-}
+	ecs.SetResource(g.world, resources.Board{
+		Board: boardTiles,
+	})
 
-func (g *Game) registerComponentTypes() {
-	// Register all component types with the component manager
-	for _, componentType := range components.ComponentTypes {
-		g.world.ComponentManager.RegisterComponentType(componentType)
-	}
+	// Make the game state
+	ecs.SetResource(g.world, resources.GameState{
+		GameOver: false,
+	})
+	return nil
 }
 
 func (g *Game) Run() {
-	g.world.Logger.Println("Starting game...")
+	g.world.Logger.Info("starting game")
 
 	// Main game loop
 	for {
-		// Get the game state entity
-		gameState := g.getGameState()
-		if gameState == nil || gameState.GameOver {
+		// Get the game state
+		gameState, err := g.getGameState()
+		if err != nil {
+			g.world.Logger.Error("stopping game", "error", err)
+			break
+		}
+		if gameState.GameOver {
 			break
 		}
 
-		// Display the board
-		g.displayBoard()
+		// Display the board if it changed since it was last shown
+		if ecs.ResourceChanged[resources.Board](g.world, g.boardShown) {
+			g.boardShown = g.world.ChangeTick()
+			g.displayBoard()
+		}
 
-		// Get the player component
-		playerEnt := gameState.PlayerTurn
-		player, _ := g.componentAccess.GetPlayerComponent(gameState.PlayerTurn)
+		// Get the player whose turn it is
+		current := g.currentPlayer.Entities(g.world)
+		if len(current) != 1 {
+			g.world.Logger.Error("stopping game, expected one player with the turn", "players", len(current))
+			break
+		}
+		playerEnt := current[0]
+		player, _ := ecs.Get[components.PlayerComponent](g.world, playerEnt)
 
 		g.displayManager.ShowTurnPrompt(player.Character)
-		row, col, valid := g.inputManager.GetPlayerMove()
+		command := g.inputManager.ReadCommand()
 
-		if !valid {
-			g.world.Logger.Println("Invalid input. Please try again.")
+		switch command.Kind {
+		case input.Quit:
+			return
+		case input.Save:
+			g.save(command.Path)
+			continue
+		case input.Invalid:
+			g.displayManager.ShowMessage("Invalid input. Please try again.")
 			continue
 		}
 
-		// Create a move intent component
-		moveIntent := &components.MoveIntentComponent{
-			Row: row,
-			Col: col,
+		// Player input goes through an event so it is recorded in event logs
+		g.world.Emit(events.MoveRequestedEvent{
+			Ent: playerEnt,
+			Row: command.Row,
+			Col: command.Col,
+		})
+
+		// The world steps once per move
+		if err := g.runner.Step(); err != nil {
+			if errors.Is(err, systems.ErrCellTaken) {
+				g.displayManager.ShowMessage("That cell is already taken, try again.")
+				continue
+			}
+			if errors.Is(err, systems.ErrOffBoard) {
+				g.displayManager.ShowMessage("That cell is not on the board, try again.")
+				continue
+			}
+			g.world.Logger.Error("stopping game", "error", err)
+			break
 		}
 
-		// Add the move intent component to the player entity
-		g.world.ComponentManager.AddComponent(
-			playerEnt,
-			components.MoveIntent,
-			moveIntent,
-		)
-
-		g.world.Update()
-
 	}
 }
 
 func (g Game) displayBoard() {
-	boardEnts := g.world.ComponentManager.GetAllEntitiesWithComponent(components.Board)
-	if len(boardEnts) == 0 {
-		return
-	}
-
-	board, hasBoardComp := g.componentAccess.GetBoardComponent(boardEnts[0])
-	if !hasBoardComp {
+	board, err := ecs.Resource[resources.Board](g.world)
+	if err != nil {
+		g.world.Logger.Error("cannot display board", "error", err)
 		return
 	}
 
 	// Get the display characters from player components
 	var p1Char, p2Char string
-	playerEnts := g.world.ComponentManager.GetAllEntitiesWithComponent(components.Player)
-	for _, ent := range playerEnts {
-		player, _ := g.componentAccess.GetPlayerComponent(ent)
+	for _, name := range []string{playerX, playerO} {
+		playerEnt, _ := g.world.Lookup(name)
+		player, exists := ecs.Get[components.PlayerComponent](g.world, playerEnt)
+		if !exists {
+			g.world.Logger.Error("cannot display board, player is missing", "player", name)
+			return
+		}
 		if player.CellState == components.Player1 {
 			p1Char = player.Character
 		} else {
@@ -196,16 +255,24 @@ func (g Game) displayBoard() {
 	g.displayManager.ShowBoard(displayBoard)
 }
 
-func (g *Game) getGameState() *components.GameStateComponent {
-	gameStateEnts := g.world.ComponentManager.GetAllEntitiesWithComponent(components.GameState)
-	if len(gameStateEnts) == 0 {
-		return nil
-	}
+func (g *Game) getGameState() (*resources.GameState, error) {
+	return ecs.Resource[resources.GameState](g.world)
+}
 
-	gameState, hasComp := g.componentAccess.GetGameStateComponent(gameStateEnts[0])
-	if !hasComp {
-		return nil
-	}
+// gameInProgress is a run condition that holds until the game is over
+func gameInProgress(world *ecs.World) bool {
+	gameState, err := ecs.Resource[resources.GameState](world)
+	return err == nil && !gameState.GameOver
+}
+
+// EnableProfiling records how long each system takes, and labels CPU
+// profiles with the system being run
+func (g *Game) EnableProfiling() {
+	g.world.EnableMetrics(true)
+	g.world.EnablePprofLabels(true)
+}
 
-	return gameState
+// WriteProfile writes the timings recorded since EnableProfiling as a table
+func (g *Game) WriteProfile(out io.Writer) error {
+	return g.world.Metrics().WriteTable(out)
 }
@@ -0,0 +1,38 @@
+package game
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"ttt/pkg/ecs"
+)
+
+// Record appends every event of the game to out as JSON lines, which
+// Replay can check the game against later
+func (g *Game) Record(out io.Writer) {
+	g.world.SetEventLog(ecs.NewEventLog(out))
+}
+
+// Replay plays back the moves in an event log recorded from a new game and
+// checks that they lead to the same events. It is used in place of Run on
+// an initialized game.
+func (g *Game) Replay(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	entries, err := ecs.ReadEventLog(f)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+	updates, err := ecs.Replay(g.world, entries, g.runner.Step)
+	if err != nil {
+		return fmt.Errorf("replaying %s: %w", path, err)
+	}
+	g.displayBoard()
+	g.displayManager.ShowMessage(fmt.Sprintf("Replayed %d updates from %s, all events match", updates, path))
+	return nil
+}
@@ -0,0 +1,32 @@
+package resources
+
+import (
+	"ttt/internal/game/components"
+	"ttt/pkg/ecs"
+)
+
+// GameState tracks whether the game has ended. Whose turn it is is tracked
+// with the components.CurrentTurn tag.
+type GameState struct {
+	GameOver bool
+}
+
+// Board holds the state of every cell
+type Board struct {
+	Board [][]components.CellState
+}
+
+// Clone copies the board so simulated moves don't touch the original
+func (b Board) Clone() Board {
+	board := make([][]components.CellState, len(b.Board))
+	for i, row := range b.Board {
+		board[i] = append([]components.CellState(nil), row...)
+	}
+	return Board{Board: board}
+}
+
+func init() {
+	// Names identify the resources in saved games
+	ecs.RegisterComponent[GameState]("game_state")
+	ecs.RegisterComponent[Board]("board")
+}
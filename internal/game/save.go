@@ -0,0 +1,74 @@
+package game
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"ttt/pkg/ecs"
+)
+
+// DefaultSavePath is where "save" writes when no file is given
+const DefaultSavePath = "ttt-save.json"
+
+// codecFor picks the snapshot format from the file extension
+func codecFor(path string) ecs.Codec {
+	if filepath.Ext(path) == ".gob" {
+		return ecs.GobCodec
+	}
+	return ecs.JSONCodec
+}
+
+// Save writes the current game to path, as gob if it ends in .gob and JSON
+// otherwise. The game is written to a temporary file that replaces path once
+// complete, so a failed save leaves an earlier save intact.
+func (g *Game) Save(path string) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name()) // fails harmlessly once renamed
+	// CreateTemp makes the file private, saves are as readable as before
+	if err := f.Chmod(0o644); err != nil {
+		f.Close()
+		return err
+	}
+	if err := g.world.Snapshot(codecFor(path), f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// Load sets up the game from a file written by Save, in place of Initialize
+func (g *Game) Load(path string) error {
+	if err := g.setup(); err != nil {
+		return err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := g.world.Restore(codecFor(path), f); err != nil {
+		return fmt.Errorf("loading %s: %w", path, err)
+	}
+	return nil
+}
+
+// save handles the in-game save command
+func (g *Game) save(path string) {
+	if path == "" {
+		path = DefaultSavePath
+	}
+	if err := g.Save(path); err != nil {
+		g.displayManager.ShowMessage(fmt.Sprintf("Could not save the game: %v", err))
+		return
+	}
+	g.displayManager.ShowMessage("Game saved to " + path)
+}
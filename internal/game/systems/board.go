@@ -1,60 +1,71 @@
 package systems
 
 import (
+	"fmt"
+
 	"ttt/internal/game/components"
 	"ttt/internal/game/events"
+	"ttt/internal/game/resources"
 	"ttt/pkg/ecs"
 )
 
 // BoardSystem checks the state of the board, and sends out winner / tie events
 type BoardSystem struct {
-	ComponentAccess *components.ComponentAccess
+	players   *ecs.Query1[components.PlayerComponent]
+	lastCheck ecs.Tick
 }
 
-func (b *BoardSystem) Update(world *ecs.World) {
-	// Get the board
-	boardEnts := world.ComponentManager.GetAllEntitiesWithComponent(components.Board)
-	if len(boardEnts) == 0 {
-		return
+func NewBoardSystem() *BoardSystem {
+	return &BoardSystem{
+		players: ecs.NewQuery1[components.PlayerComponent](),
+	}
+}
+
+func (b *BoardSystem) Update(world *ecs.World) error {
+	// Nothing to check unless a move was made since last time
+	if !ecs.ResourceChanged[resources.Board](world, b.lastCheck) {
+		return nil
 	}
+	b.lastCheck = world.ChangeTick()
 
-	board, hasBoardComp := b.ComponentAccess.GetBoardComponent(boardEnts[0])
-	if !hasBoardComp {
-		return
+	// Get the board
+	board, err := ecs.Resource[resources.Board](world)
+	if err != nil {
+		return err
 	}
 
 	// Get the players
-	playerEnts := world.ComponentManager.GetAllEntitiesWithComponent(components.Player)
+	playerEnts := b.players.Entities(world)
 	if len(playerEnts) != 2 {
-		return
+		return fmt.Errorf("expected 2 players, found %d", len(playerEnts))
 	}
 
 	for _, playerEnt := range playerEnts {
-		player, hasPlayerComp := b.ComponentAccess.GetPlayerComponent(playerEnt)
+		player, hasPlayerComp := ecs.Get[components.PlayerComponent](world, playerEnt)
 		if !hasPlayerComp {
 			continue
 		}
 
 		// Check for winner
 		if b.checkIfWin(board, player) {
-			world.QueueEvent(events.PlayerWonEvent{
+			ecs.Publish(world, events.PlayerWonEvent{
 				Ent: playerEnt,
 			})
-			return
+			return nil
 		}
 	}
 
 	// Check for a draw (No more spaces to move and no winner)
 	if b.checkIfDraw(board) {
-		world.QueueEvent(events.TieEvent{
-			Ent: -1,
+		ecs.Publish(world, events.TieEvent{
+			Ent: ecs.NoEntity,
 		})
-		return
 	}
+	return nil
 }
 
 func (b BoardSystem) checkIfWin(
-	board *components.BoardComponent,
+	board *resources.Board,
 	player *components.PlayerComponent,
 ) bool {
 	// Check rows
@@ -98,7 +109,7 @@ func (b BoardSystem) checkIfWin(
 	return false
 }
 
-func (b BoardSystem) checkIfDraw(board *components.BoardComponent) bool {
+func (b BoardSystem) checkIfDraw(board *resources.Board) bool {
 	for i := range 3 {
 		for j := range 3 {
 			if board.Board[i][j] == components.Empty {
@@ -1,64 +1,77 @@
 package systems
 
 import (
+	"errors"
+	"fmt"
+
 	"ttt/internal/game/components"
 	"ttt/internal/game/events"
+	"ttt/internal/game/resources"
 	"ttt/pkg/ecs"
 )
 
+// ErrCellTaken is returned for a move onto a cell that is not empty
+var ErrCellTaken = errors.New("cell is already taken")
+
+// ErrOffBoard is returned for a move onto a cell outside the board, which
+// can only come from a replayed or hand-edited event log
+var ErrOffBoard = errors.New("cell is not on the board")
+
 // MoveSystem is responsible for evaluating and executing player moves
 type MoveSystem struct {
-	ComponentAccess *components.ComponentAccess
+	intents *ecs.Query2[components.MoveIntentComponent, components.PlayerComponent]
 }
 
-func (m *MoveSystem) Update(world *ecs.World) {
-	// Get all entities with a move intent component
-	moveIntentEnts := world.ComponentManager.GetAllEntitiesWithComponent(components.MoveIntent)
-	if len(moveIntentEnts) == 0 {
-		return
+func NewMoveSystem() *MoveSystem {
+	return &MoveSystem{
+		intents: ecs.NewQuery2[components.MoveIntentComponent, components.PlayerComponent](),
 	}
+}
 
-	// Get the board entity
-	boardEnts := world.ComponentManager.GetAllEntitiesWithComponent(components.Board)
-	if len(boardEnts) == 0 {
-		return
+func (m *MoveSystem) Update(world *ecs.World) error {
+	// Get all players with a move intent component
+	if len(m.intents.Entities(world)) == 0 {
+		return nil
 	}
 
-	// Get the board component
-	board, hasBoardComp := m.ComponentAccess.GetBoardComponent(boardEnts[0])
-	if !hasBoardComp {
-		return
+	// Get the board
+	board, err := ecs.Resource[resources.Board](world)
+	if err != nil {
+		return err
 	}
 
-	for _, entity := range moveIntentEnts {
-		// Get the move intent component
-		moveIntent, _ := m.ComponentAccess.GetMoveIntentComponent(entity)
-
-		// Check if the move is valid
-		if board.Board[moveIntent.Row][moveIntent.Col] != components.Empty {
-			// Invalid move, remove the move intent component
-			world.ComponentManager.RemoveComponent(entity, components.MoveIntent)
-			continue
-		} else {
-			// Get the player component
-			player, hasPlayerComp := m.ComponentAccess.GetPlayerComponent(entity)
-			if !hasPlayerComp {
-				continue
-			}
+	var errs []error
 
-			// Update the board
-			board.Board[moveIntent.Row][moveIntent.Col] = player.CellState
+	m.intents.Each(world, func(
+		entity ecs.Entity,
+		moveIntent *components.MoveIntentComponent,
+		player *components.PlayerComponent,
+	) {
+		row, col := moveIntent.Row, moveIntent.Col
 
-			// Remove the move intent component
-			world.ComponentManager.RemoveComponent(entity, components.MoveIntent)
+		// Remove the move intent component, whether or not the move is valid
+		world.Commands().RemoveComponent(entity, ecs.ID[components.MoveIntentComponent]())
 
-			// Send out events
-			world.QueueEvent(events.PlayerMovedEvent{
-				Ent: entity,
-				Row: moveIntent.Row,
-				Col: moveIntent.Col,
-			})
+		// Check if the move is valid
+		if row < 0 || row >= len(board.Board) || col < 0 || col >= len(board.Board[row]) {
+			errs = append(errs, fmt.Errorf("%w: row %d, column %d", ErrOffBoard, row, col))
+			return
 		}
-	}
+		if board.Board[row][col] != components.Empty {
+			errs = append(errs, fmt.Errorf("%w: row %d, column %d", ErrCellTaken, row, col))
+			return
+		}
+
+		// Update the board
+		board.Board[row][col] = player.CellState
+		ecs.MarkResourceChanged[resources.Board](world)
 
+		// Send out events
+		ecs.Publish(world, events.PlayerMovedEvent{
+			Ent: entity,
+			Row: row,
+			Col: col,
+		})
+	})
+	return errors.Join(errs...)
 }
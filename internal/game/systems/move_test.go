@@ -0,0 +1,41 @@
+package systems
+
+import (
+	"errors"
+	"testing"
+
+	"ttt/internal/game/components"
+	"ttt/internal/game/resources"
+	"ttt/pkg/ecs"
+)
+
+func TestMoveSystemRejectsCellsOffTheBoard(t *testing.T) {
+	for _, move := range []components.MoveIntentComponent{
+		{Row: 5, Col: 0},
+		{Row: 0, Col: 3},
+		{Row: -1, Col: 1},
+	} {
+		world := ecs.NewWorld(nil)
+		world.SetErrorPolicy(ecs.ReturnErrors)
+		world.AddSystem(NewMoveSystem())
+		ecs.SetResource(world, resources.Board{Board: [][]components.CellState{
+			make([]components.CellState, 3),
+			make([]components.CellState, 3),
+			make([]components.CellState, 3),
+		}})
+		player := world.EntityManager.CreateEntity()
+		if err := ecs.Add(world, player, components.PlayerComponent{CellState: components.Player1}); err != nil {
+			t.Fatal(err)
+		}
+		if err := ecs.Add(world, player, move); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := world.Update(); !errors.Is(err, ErrOffBoard) {
+			t.Errorf("move to row %d, column %d: Update returned %v, want %v", move.Row, move.Col, err, ErrOffBoard)
+		}
+		if ecs.Has[components.MoveIntentComponent](world, player) {
+			t.Errorf("move to row %d, column %d: intent was not removed", move.Row, move.Col)
+		}
+	}
+}
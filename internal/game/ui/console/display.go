@@ -30,3 +30,7 @@ func (c ConsoleDisplayManager) ShowTurnPrompt(player string) {
 func (c ConsoleDisplayManager) ShowGameResult(result string) {
 	fmt.Println(result)
 }
+
+func (c ConsoleDisplayManager) ShowMessage(message string) {
+	fmt.Println(message)
+}
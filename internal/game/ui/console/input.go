@@ -1,19 +1,45 @@
 package console
 
 import (
-	"fmt"
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+
+	"ttt/internal/input"
 )
 
-type ConsoleInputManager struct{}
+type ConsoleInputManager struct {
+	reader *bufio.Reader
+}
 
 func NewConsoleInputManager() ConsoleInputManager {
-	return ConsoleInputManager{}
+	return ConsoleInputManager{reader: bufio.NewReader(os.Stdin)}
 }
 
-func (c ConsoleInputManager) GetPlayerMove() (row, col int, valid bool) {
-	_, err := fmt.Scanf("%d %d", &col, &row)
-	if err != nil || row < 0 || row > 2 || col < 0 || col > 2 {
-		return 0, 0, false
+// ReadCommand reads one line: either a move as "column row", or "save [file]"
+func (c ConsoleInputManager) ReadCommand() input.Command {
+	line, err := c.reader.ReadString('\n')
+	if err != nil && line == "" {
+		return input.Command{Kind: input.Quit}
+	}
+
+	fields := strings.Fields(line)
+	if len(fields) >= 1 && fields[0] == "save" {
+		cmd := input.Command{Kind: input.Save}
+		if len(fields) > 1 {
+			cmd.Path = fields[1]
+		}
+		return cmd
+	}
+
+	if len(fields) != 2 {
+		return input.Command{Kind: input.Invalid}
+	}
+	col, colErr := strconv.Atoi(fields[0])
+	row, rowErr := strconv.Atoi(fields[1])
+	if colErr != nil || rowErr != nil || row < 0 || row > 2 || col < 0 || col > 2 {
+		return input.Command{Kind: input.Invalid}
 	}
-	return row, col, true
+	return input.Command{Kind: input.Move, Row: row, Col: col}
 }
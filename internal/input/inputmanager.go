@@ -1,5 +1,21 @@
 package input
 
+type CommandKind int
+
+const (
+	Invalid CommandKind = iota
+	Move
+	Save
+	Quit
+)
+
+// Command is a single instruction from the player
+type Command struct {
+	Kind     CommandKind
+	Row, Col int    // for Move
+	Path     string // for Save, empty for the default location
+}
+
 type InputManager interface {
-	GetPlayerMove() (row, col int, valid bool)
+	ReadCommand() Command
 }
@@ -0,0 +1,200 @@
+package ecs
+
+import (
+	"encoding/binary"
+	"slices"
+)
+
+// column is a contiguous array holding one component type for an archetype
+type column interface {
+	// appendFrom appends a copy of src's value at row; src must hold the same type
+	appendFrom(src column, row int)
+	swapRemove(row int)
+	// get returns a pointer to the value at row, boxed as any
+	get(row int) any
+	len() int
+	// clone returns a copy of the column, see Cloner
+	clone() column
+}
+
+type typedColumn[T any] struct {
+	data []T
+}
+
+func newTypedColumn[T any]() column {
+	return &typedColumn[T]{}
+}
+
+func (c *typedColumn[T]) appendFrom(src column, row int) {
+	c.data = append(c.data, src.(*typedColumn[T]).data[row])
+}
+
+func (c *typedColumn[T]) swapRemove(row int) {
+	last := len(c.data) - 1
+	c.data[row] = c.data[last]
+	var zero T
+	c.data[last] = zero
+	c.data = c.data[:last]
+}
+
+func (c *typedColumn[T]) get(row int) any {
+	return &c.data[row]
+}
+
+func (c *typedColumn[T]) len() int {
+	return len(c.data)
+}
+
+// archetype stores every entity that has exactly the same set of components.
+// Each component type gets its own contiguous column, and row i of every
+// column belongs to entities[i].
+type archetype struct {
+	id       int
+	ids      []ComponentID      // sorted
+	columns  []column           // parallel to ids
+	ticks    [][]componentTicks // parallel to columns, see change.go
+	index    []int              // component ID -> position in columns, -1 if absent
+	entities []Entity
+
+	// Cached transitions to neighbouring archetypes
+	withEdges    map[ComponentID]*archetype
+	withoutEdges map[ComponentID]*archetype
+}
+
+func newArchetype(id int, ids []ComponentID) *archetype {
+	a := &archetype{
+		id:           id,
+		ids:          ids,
+		columns:      make([]column, len(ids)),
+		ticks:        make([][]componentTicks, len(ids)),
+		withEdges:    make(map[ComponentID]*archetype),
+		withoutEdges: make(map[ComponentID]*archetype),
+	}
+	if len(ids) > 0 {
+		a.index = make([]int, ids[len(ids)-1]+1)
+		for i := range a.index {
+			a.index[i] = -1
+		}
+	}
+	for i, cid := range ids {
+		a.index[cid] = i
+		a.columns[i] = registry.newColumn(cid)
+	}
+	return a
+}
+
+// column returns the position of the component's column, or -1 if the
+// archetype does not contain it
+func (a *archetype) column(id ComponentID) int {
+	if int(id) >= len(a.index) {
+		return -1
+	}
+	return a.index[id]
+}
+
+func (a *archetype) has(id ComponentID) bool {
+	return a.column(id) >= 0
+}
+
+// archetypeKey builds the lookup key for a sorted component set
+func archetypeKey(ids []ComponentID) string {
+	key := make([]byte, 0, len(ids)*4)
+	for _, id := range ids {
+		key = binary.LittleEndian.AppendUint32(key, uint32(id))
+	}
+	return string(key)
+}
+
+// getArchetype returns the archetype for the sorted component set, creating it if needed
+func (cm *ComponentManager) getArchetype(ids []ComponentID) *archetype {
+	key := archetypeKey(ids)
+	if a, exists := cm.archetypeIndex[key]; exists {
+		return a
+	}
+	a := newArchetype(len(cm.archetypes), ids)
+	cm.archetypes = append(cm.archetypes, a)
+	cm.archetypeIndex[key] = a
+	return a
+}
+
+// archetypeWith returns the archetype reached by adding id to from (which may be nil)
+func (cm *ComponentManager) archetypeWith(from *archetype, id ComponentID) *archetype {
+	if from == nil {
+		return cm.getArchetype([]ComponentID{id})
+	}
+	if to, exists := from.withEdges[id]; exists {
+		return to
+	}
+	ids := slices.Clone(from.ids)
+	pos, _ := slices.BinarySearch(ids, id)
+	ids = slices.Insert(ids, pos, id)
+	to := cm.getArchetype(ids)
+	from.withEdges[id] = to
+	to.withoutEdges[id] = from
+	return to
+}
+
+// archetypeWithout returns the archetype reached by removing id from from,
+// or nil if the entity would be left without components
+func (cm *ComponentManager) archetypeWithout(from *archetype, id ComponentID) *archetype {
+	if len(from.ids) == 1 {
+		return nil
+	}
+	if to, exists := from.withoutEdges[id]; exists {
+		return to
+	}
+	ids := slices.DeleteFunc(slices.Clone(from.ids), func(c ComponentID) bool { return c == id })
+	to := cm.getArchetype(ids)
+	from.withoutEdges[id] = to
+	to.withEdges[id] = from
+	return to
+}
+
+// moveEntity moves entity from its current archetype to dst, copying every
+// component the two archetypes share. Columns of dst that the source does
+// not have are left for the caller to fill. It returns the entity's new row.
+func (cm *ComponentManager) moveEntity(entity Entity, dst *archetype) int {
+	rec := &cm.records[entity.Index()]
+	src, srcRow := rec.arch, rec.row
+
+	if dst != nil {
+		if src != nil {
+			for i, id := range dst.ids {
+				if from := src.column(id); from >= 0 {
+					dst.columns[i].appendFrom(src.columns[from], srcRow)
+					dst.ticks[i] = append(dst.ticks[i], src.ticks[from][srcRow])
+				}
+			}
+		}
+		dst.entities = append(dst.entities, entity)
+	}
+
+	if src != nil {
+		cm.removeRow(src, srcRow)
+	}
+
+	rec.arch = dst
+	rec.row = -1
+	if dst != nil {
+		rec.row = len(dst.entities) - 1
+	}
+	cm.observe(entity, src, dst)
+	return rec.row
+}
+
+// removeRow swap-removes a row from an archetype and fixes up the record of
+// the entity that was moved into its place
+func (cm *ComponentManager) removeRow(a *archetype, row int) {
+	last := len(a.entities) - 1
+	for i, col := range a.columns {
+		col.swapRemove(row)
+		a.ticks[i][row] = a.ticks[i][last]
+		a.ticks[i] = a.ticks[i][:last]
+	}
+	if row != last {
+		moved := a.entities[last]
+		a.entities[row] = moved
+		cm.records[moved.Index()].row = row
+	}
+	a.entities = a.entities[:last]
+}
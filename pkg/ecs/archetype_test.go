@@ -0,0 +1,133 @@
+package ecs
+
+import (
+	"maps"
+	"slices"
+	"testing"
+)
+
+type benchPos struct{ X, Y float64 }
+type benchVel struct{ X, Y float64 }
+type benchFlag struct{ On bool }
+
+const benchEntities = 10000
+
+// mapStore mirrors the storage components had before archetypes, one map
+// per component type holding pointers, as a baseline for the benchmarks
+type mapStore struct {
+	data map[ComponentID]map[Entity]any
+}
+
+func mapAdd[T any](s *mapStore, e Entity, v T) {
+	m, exists := s.data[ID[T]()]
+	if !exists {
+		m = make(map[Entity]any)
+		s.data[ID[T]()] = m
+	}
+	m[e] = &v
+}
+
+func mapGet[T any](s *mapStore, e Entity) (*T, bool) {
+	v, exists := s.data[ID[T]()][e]
+	if !exists {
+		return nil, false
+	}
+	return v.(*T), true
+}
+
+func mapRemove[T any](s *mapStore, e Entity) {
+	delete(s.data[ID[T]()], e)
+}
+
+// mapEach2 iterates like the map based queries did: collect the entities
+// of the first type in order, then look up both components of each
+func mapEach2[A, B any](s *mapStore, fn func(e Entity, a *A, b *B)) {
+	for _, e := range slices.Sorted(maps.Keys(s.data[ID[A]()])) {
+		a, _ := mapGet[A](s, e)
+		b, exists := mapGet[B](s, e)
+		if exists {
+			fn(e, a, b)
+		}
+	}
+}
+
+// newBenchWorlds builds the same entities in a world and in a map store
+func newBenchWorlds() (*World, *mapStore, []Entity) {
+	w := NewWorld(nil)
+	s := &mapStore{data: make(map[ComponentID]map[Entity]any)}
+	for i := range benchEntities {
+		e := w.EntityManager.CreateEntity()
+		Add(w, e, benchPos{})
+		Add(w, e, benchVel{1, 1})
+		mapAdd(s, e, benchPos{})
+		mapAdd(s, e, benchVel{1, 1})
+		if i%3 == 0 {
+			Add(w, e, benchFlag{})
+			mapAdd(s, e, benchFlag{})
+		}
+	}
+	return w, s, w.EntityManager.GetAllEntities()
+}
+
+func BenchmarkQueryEach(b *testing.B) {
+	w, s, _ := newBenchWorlds()
+	move := func(_ Entity, p *benchPos, v *benchVel) {
+		p.X += v.X
+		p.Y += v.Y
+	}
+	b.Run("archetype", func(b *testing.B) {
+		q := NewQuery2[benchPos, benchVel]()
+		b.ReportAllocs()
+		for range b.N {
+			q.Each(w, move)
+		}
+	})
+	b.Run("map", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			mapEach2(s, move)
+		}
+	})
+}
+
+func BenchmarkGet(b *testing.B) {
+	w, s, entities := newBenchWorlds()
+	b.Run("archetype", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := range b.N {
+			p, _ := Get[benchPos](w, entities[i%len(entities)])
+			p.X++
+		}
+	})
+	b.Run("map", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := range b.N {
+			p, _ := mapGet[benchPos](s, entities[i%len(entities)])
+			p.X++
+		}
+	})
+}
+
+func BenchmarkAddRemove(b *testing.B) {
+	w, s, entities := newBenchWorlds()
+	b.Run("archetype", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := range b.N {
+			e := entities[i%len(entities)]
+			Add(w, e, benchFlag{true})
+			Remove[benchFlag](w, e)
+			// Removals are logged until the end of the next update
+			if i%len(entities) == 0 {
+				w.ComponentManager.rotateRemoved()
+			}
+		}
+	})
+	b.Run("map", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := range b.N {
+			e := entities[i%len(entities)]
+			mapAdd(s, e, benchFlag{true})
+			mapRemove[benchFlag](s, e)
+		}
+	})
+}
@@ -0,0 +1,130 @@
+package ecs
+
+// Tick is a point in a world's change history. Components and resources
+// record the tick at which they were added and last changed, and code that
+// reacts to changes compares those against the tick it last looked at.
+type Tick uint64
+
+// componentTicks records when a component or resource was added and last changed
+type componentTicks struct {
+	added   Tick
+	changed Tick
+}
+
+// ChangeTick returns the current change tick and advances it, so anything
+// changed from now on is newer than the returned tick. Code looking for
+// changes keeps the tick from its previous look and passes it as since.
+func (w *World) ChangeTick() Tick {
+	return Tick(w.ComponentManager.tick.Add(1) - 1)
+}
+
+// now returns the tick new changes are stamped with
+func (cm *ComponentManager) now() Tick {
+	return Tick(cm.tick.Load())
+}
+
+// markChanged stamps the component with the current tick, if entity has it
+func (cm *ComponentManager) markChanged(entity Entity, id ComponentID) {
+	arch, row := cm.lookup(entity)
+	if arch == nil {
+		return
+	}
+	if col := arch.column(id); col >= 0 {
+		arch.ticks[col][row].changed = cm.now()
+	}
+}
+
+// MarkChanged flags the entity's component of type T as changed. Writes
+// through pointers from Get or queries are not tracked, so systems call
+// this (or use GetMut) after modifying a component others react to.
+func MarkChanged[T any](w *World, entity Entity) {
+	w.ComponentManager.markChanged(entity, ID[T]())
+}
+
+// Added restricts a query to entities whose listed components were added
+// since the query last returned its entities. The components are required.
+func Added(ids ...ComponentID) QueryTerm {
+	return func(f *Filter) {
+		f.all = append(f.all, ids...)
+		f.added = append(f.added, ids...)
+	}
+}
+
+// Changed restricts a query to entities whose listed components were added
+// or changed since the query last returned its entities. The components are
+// required.
+func Changed(ids ...ComponentID) QueryTerm {
+	return func(f *Filter) {
+		f.all = append(f.all, ids...)
+		f.changed = append(f.changed, ids...)
+	}
+}
+
+func (f *Filter) tracksChanges() bool {
+	return len(f.added) > 0 || len(f.changed) > 0
+}
+
+// changedSince returns the entities whose tracked components were added or
+// changed after since
+func (f *Filter) changedSince(cm *ComponentManager, entities []Entity, since Tick) []Entity {
+	result := []Entity{}
+	for _, e := range entities {
+		arch, row := cm.lookup(e)
+		if arch != nil && f.changedAt(arch, row, since) {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+func (f *Filter) changedAt(arch *archetype, row int, since Tick) bool {
+	for _, id := range f.added {
+		col := arch.column(id)
+		if col < 0 || arch.ticks[col][row].added <= since {
+			return false
+		}
+	}
+	for _, id := range f.changed {
+		col := arch.column(id)
+		if col < 0 || arch.ticks[col][row].changed <= since {
+			return false
+		}
+	}
+	return true
+}
+
+// removal records a component leaving an entity
+type removal struct {
+	entity Entity
+	id     ComponentID
+	tick   Tick
+}
+
+// Removed returns the entities that lost their component with the given ID
+// after since, including entities that were removed altogether. Removals
+// are only kept for the current and the previous Update, so readers must
+// look at least once per update.
+func (w *World) Removed(id ComponentID, since Tick) []Entity {
+	cm := w.ComponentManager
+	entities := []Entity{}
+	for _, log := range [][]removal{cm.removedBefore, cm.removed} {
+		for _, r := range log {
+			if r.id == id && r.tick > since {
+				entities = append(entities, r.entity)
+			}
+		}
+	}
+	return entities
+}
+
+// recordRemoval logs the removal and runs the OnRemove hooks while the
+// component is still in place
+func (cm *ComponentManager) recordRemoval(entity Entity, arch *archetype, row int, id ComponentID) {
+	cm.removed = append(cm.removed, removal{entity: entity, id: id, tick: cm.now()})
+	cm.runHooks(onRemove, entity, arch, row, id)
+}
+
+// rotateRemoved drops the removals from the previous update
+func (cm *ComponentManager) rotateRemoved() {
+	cm.removedBefore, cm.removed = cm.removed, cm.removedBefore[:0]
+}
@@ -0,0 +1,164 @@
+package ecs
+
+import "maps"
+
+// Cloner is implemented by values that need more than a shallow copy to be
+// duplicated, such as components holding slices, maps or pointers. Clone
+// must return a copy that shares no mutable state with the original.
+type Cloner[T any] interface {
+	Clone() T
+}
+
+// cloneValue copies v, deeply if T implements Cloner[T] on either its value
+// or pointer receiver
+func cloneValue[T any](v *T) T {
+	if c, ok := any(*v).(Cloner[T]); ok {
+		return c.Clone()
+	}
+	if c, ok := any(v).(Cloner[T]); ok {
+		return c.Clone()
+	}
+	return *v
+}
+
+func (c *typedColumn[T]) clone() column {
+	data := make([]T, len(c.data), cap(c.data))
+	for i := range c.data {
+		data[i] = cloneValue(&c.data[i])
+	}
+	return &typedColumn[T]{data: data}
+}
+
+// Clone returns an independent copy of the world for search and what-if
+// simulation. Entities keep their handles, components and resources are
+// copied (deeply for those implementing Cloner), and queued events and
+// commands are carried over, so mutating the clone never affects the original.
+//
+// The clone runs the same systems with the same configuration and error
+// policy, and is halted if the original is. Systems that
+// implement Cloner[System] are cloned, the rest are shared with the original,
+// so two worlds sharing stateful systems must not be updated concurrently.
+// Observers are copied, since their callbacks are handed the world. Event
+// handlers and component hooks are not, since they usually close over state
+// that belongs to the original world; register them on the clone as needed.
+func (w *World) Clone() *World {
+	em := w.EntityManager.clone()
+	cm := w.ComponentManager.clone(em)
+
+	// Observers are shared between the component manager, the world and
+	// the systems they trigger, so each gets the same copy
+	observers := make(map[*observer]*observer, len(w.ComponentManager.observers))
+	for _, o := range w.ComponentManager.observers {
+		observers[o] = o.clone()
+		cm.observers = append(cm.observers, observers[o])
+	}
+
+	clone := &World{
+		EntityManager:    em,
+		ComponentManager: cm,
+		scheduler:        w.scheduler.clone(observers),
+		commands:         newCommands(em),
+		resources:        make(map[ComponentID]resource, len(w.resources)),
+		events:           newEventBus(),
+		errorPolicy:      w.errorPolicy,
+		haltedBy:         w.haltedBy,
+		Logger:           w.Logger,
+	}
+	for id, r := range w.resources {
+		clone.resources[id] = r.clone()
+	}
+	for _, o := range w.observers {
+		clone.observers = append(clone.observers, observers[o])
+	}
+
+	w.commands.mu.Lock()
+	clone.commands.queue = append([]command(nil), w.commands.queue...)
+	w.commands.mu.Unlock()
+
+	w.events.mu.Lock()
+	clone.events.queue = append([]EventInterface(nil), w.events.queue...)
+	w.events.mu.Unlock()
+
+	return clone
+}
+
+func (em *EntityManager) clone() *EntityManager {
+	return &EntityManager{
+		slots:     append([]entitySlot(nil), em.slots...),
+		free:      append([]uint32(nil), em.free...),
+		count:     em.count,
+		version:   em.version,
+		nextIndex: em.nextIndex,
+		names:     maps.Clone(em.names),
+	}
+}
+
+func (cm *ComponentManager) clone(entities *EntityManager) *ComponentManager {
+	clone := &ComponentManager{
+		entities:       entities,
+		archetypes:     make([]*archetype, len(cm.archetypes)),
+		archetypeIndex: make(map[string]*archetype, len(cm.archetypeIndex)),
+		version:        cm.version,
+		removed:        append([]removal(nil), cm.removed...),
+		removedBefore:  append([]removal(nil), cm.removedBefore...),
+	}
+	clone.tick.Store(cm.tick.Load())
+
+	// Copy the archetypes first so the edges can be pointed at the copies
+	for i, arch := range cm.archetypes {
+		columns := make([]column, len(arch.columns))
+		ticks := make([][]componentTicks, len(arch.ticks))
+		for j, col := range arch.columns {
+			columns[j] = col.clone()
+			ticks[j] = append([]componentTicks(nil), arch.ticks[j]...)
+		}
+		clone.archetypes[i] = &archetype{
+			id:           arch.id,
+			ids:          arch.ids,   // never modified after creation
+			index:        arch.index, // never modified after creation
+			columns:      columns,
+			ticks:        ticks,
+			entities:     append([]Entity(nil), arch.entities...),
+			withEdges:    make(map[ComponentID]*archetype, len(arch.withEdges)),
+			withoutEdges: make(map[ComponentID]*archetype, len(arch.withoutEdges)),
+		}
+		clone.archetypeIndex[archetypeKey(arch.ids)] = clone.archetypes[i]
+	}
+	for i, arch := range cm.archetypes {
+		for id, to := range arch.withEdges {
+			clone.archetypes[i].withEdges[id] = clone.archetypes[to.id]
+		}
+		for id, to := range arch.withoutEdges {
+			clone.archetypes[i].withoutEdges[id] = clone.archetypes[to.id]
+		}
+	}
+
+	clone.records = make([]record, len(cm.records))
+	for i, rec := range cm.records {
+		clone.records[i] = rec
+		if rec.arch != nil {
+			clone.records[i].arch = clone.archetypes[rec.arch.id]
+		}
+	}
+	return clone
+}
+
+func (s *scheduler) clone(observers map[*observer]*observer) scheduler {
+	clone := scheduler{
+		entries: make([]*systemEntry, len(s.entries)),
+		dirty:   true,
+	}
+	for i, entry := range s.entries {
+		copied := *entry
+		if c, ok := entry.system.(Cloner[System]); ok {
+			copied.system = c.Clone()
+		}
+		copied.triggers = make([]trigger, len(entry.triggers))
+		for j, t := range entry.triggers {
+			copied.triggers[j] = t
+			copied.triggers[j].observer = observers[t.observer]
+		}
+		clone.entries[i] = &copied
+	}
+	return clone
+}
@@ -0,0 +1,161 @@
+package ecs
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+// failingSystem fails every update with err
+type failingSystem struct{ err error }
+
+func (s failingSystem) Update(*World) error { return s.err }
+
+func TestCloneKeepsErrorPolicy(t *testing.T) {
+	errIllegal := errors.New("illegal move")
+
+	w := NewWorld(nil)
+	w.SetErrorPolicy(ReturnErrors)
+	w.AddSystem(failingSystem{errIllegal})
+	if err := w.Clone().Update(); !errors.Is(err, errIllegal) {
+		t.Fatalf("clone of a ReturnErrors world: Update returned %v, want %v", err, errIllegal)
+	}
+
+	w.SetErrorPolicy(HaltOnError)
+	if err := w.Update(); !errors.Is(err, errIllegal) {
+		t.Fatalf("Update returned %v, want %v", err, errIllegal)
+	}
+	clone := w.Clone()
+	if err := clone.Update(); !errors.Is(err, ErrWorldHalted) {
+		t.Fatalf("clone of a halted world: Update returned %v, want %v", err, ErrWorldHalted)
+	}
+	clone.Resume()
+	if err := w.Update(); !errors.Is(err, ErrWorldHalted) {
+		t.Fatalf("resuming the clone resumed the original, Update returned %v", err)
+	}
+}
+
+type cloneMarker struct{ N int }
+
+type cloneEvent struct{ N int }
+
+func (cloneEvent) Type() EventType { return "clone" }
+func (cloneEvent) Entity() Entity  { return NoEntity }
+func (e cloneEvent) Data() any     { return e }
+
+// cloneCells holds a slice, so copies must go through Clone
+type cloneCells struct{ C []int }
+
+func (c cloneCells) Clone() cloneCells {
+	return cloneCells{C: slices.Clone(c.C)}
+}
+
+// cloneGrid is a resource holding nested slices
+type cloneGrid struct{ Rows [][]int }
+
+func (g cloneGrid) Clone() cloneGrid {
+	rows := make([][]int, len(g.Rows))
+	for i, row := range g.Rows {
+		rows[i] = slices.Clone(row)
+	}
+	return cloneGrid{Rows: rows}
+}
+
+// follows is a relation kind with any number of targets
+type follows struct{}
+
+func TestCloneMutationsDoNotLeak(t *testing.T) {
+	w := NewWorld(nil)
+	SetResource(w, cloneGrid{Rows: [][]int{{0, 0}, {0, 0}}})
+
+	a := w.EntityManager.CreateEntity()
+	b := w.EntityManager.CreateEntity()
+	c := w.EntityManager.CreateEntity()
+	d := w.EntityManager.CreateEntity()
+	for _, target := range []Entity{b, c} {
+		if err := Relate[follows](w, a, target); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := Relate[ChildOf](w, b, a); err != nil {
+		t.Fatal(err)
+	}
+
+	// Pending work is carried over to the clone
+	AddComponent(w.Commands(), c, cloneMarker{1})
+	AddComponent(w.Commands(), a, cloneCells{C: []int{0, 0}})
+	w.QueueEvent(cloneEvent{1})
+
+	clone := w.Clone()
+	var originalEvents, cloneEvents []int
+	Subscribe(w, func(e cloneEvent) { originalEvents = append(originalEvents, e.N) })
+	Subscribe(clone, func(e cloneEvent) { cloneEvents = append(cloneEvents, e.N) })
+
+	// Mutate everything on the clone
+	grid, err := ResourceMut[cloneGrid](clone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	grid.Rows[0][0] = 1
+	grid.Rows[1] = append(grid.Rows[1], 2)
+	if err := Relate[follows](clone, a, d); err != nil {
+		t.Fatal(err)
+	}
+	Unrelate[follows](clone, a, b)
+	if err := Relate[ChildOf](clone, b, d); err != nil {
+		t.Fatal(err)
+	}
+	AddComponent(clone.Commands(), d, cloneMarker{2})
+	clone.Commands().RemoveEntity(c)
+	clone.QueueEvent(cloneEvent{2})
+	if err := clone.Update(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The clone ran the carried over and its own work
+	if !Has[cloneMarker](clone, d) || clone.IsAlive(c) {
+		t.Error("the clone did not apply its own commands")
+	}
+	cells, _ := Get[cloneCells](clone, a)
+	cells.C[0] = 99
+	if !slices.Equal(cloneEvents, []int{1, 2}) {
+		t.Errorf("clone handled events %v, want [1 2]", cloneEvents)
+	}
+
+	// The original is unchanged
+	original, err := Resource[cloneGrid](w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if original.Rows[0][0] != 0 || len(original.Rows[1]) != 2 {
+		t.Errorf("grid mutated through the clone: %v", original.Rows)
+	}
+	if got := Targets[follows](w, a); !slices.Equal(got, []Entity{b, c}) {
+		t.Errorf("original follows %v, want [%v %v]", got, b, c)
+	}
+	if parent, _ := Parent(w, b); parent != a {
+		t.Errorf("original parent of %v is %v, want %v", b, parent, a)
+	}
+	if Has[cloneMarker](w, c) || Has[cloneMarker](w, d) {
+		t.Error("commands ran on the original before its update")
+	}
+	if len(originalEvents) != 0 {
+		t.Errorf("original handled events %v before its update", originalEvents)
+	}
+
+	if err := w.Update(); err != nil {
+		t.Fatal(err)
+	}
+	if !w.IsAlive(c) || Has[cloneMarker](w, d) {
+		t.Error("commands queued on the clone ran on the original")
+	}
+	if marker, _ := Get[cloneMarker](w, c); marker == nil || marker.N != 1 {
+		t.Error("the original did not apply its own pending command")
+	}
+	if cells, _ := Get[cloneCells](w, a); cells == nil || !slices.Equal(cells.C, []int{0, 0}) {
+		t.Errorf("component added by a carried over command mutated through the clone: %v", cells)
+	}
+	if !slices.Equal(originalEvents, []int{1}) {
+		t.Errorf("original handled events %v, want [1]", originalEvents)
+	}
+}
@@ -0,0 +1,83 @@
+package ecs
+
+import "sync"
+
+// command is a deferred structural change
+type command func(w *World) error
+
+// Commands buffers structural changes (creating and removing entities, adding
+// and removing components) so they never happen while a system is iterating.
+// Queued commands are applied in order at the end of each stage of
+// World.Update and after events are processed. It is safe for concurrent use.
+type Commands struct {
+	mu       sync.Mutex
+	entities *EntityManager
+	queue    []command
+}
+
+func newCommands(entities *EntityManager) *Commands {
+	return &Commands{entities: entities}
+}
+
+func (c *Commands) push(cmd command) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.queue = append(c.queue, cmd)
+}
+
+// CreateEntity reserves an entity and queues its creation. The handle can be
+// used in further commands right away, but the entity is only alive once the
+// commands are applied.
+func (c *Commands) CreateEntity() Entity {
+	entity := c.entities.reserve()
+	c.push(func(w *World) error {
+		w.EntityManager.activate(entity)
+		return nil
+	})
+	return entity
+}
+
+// RemoveEntity queues the removal of entity and all of its components
+func (c *Commands) RemoveEntity(entity Entity) {
+	c.push(func(w *World) error {
+		w.RemoveEntity(entity)
+		return nil
+	})
+}
+
+// RemoveComponent queues the removal of a component from entity
+func (c *Commands) RemoveComponent(entity Entity, id ComponentID) {
+	c.push(func(w *World) error {
+		w.ComponentManager.RemoveComponent(entity, id)
+		return nil
+	})
+}
+
+// AddComponent queues attaching component to entity. The entity gets its own
+// copy, deep if T implements Cloner, so a world and its clones applying the
+// same queued command never share the value.
+func AddComponent[T any](c *Commands, entity Entity, component T) {
+	c.push(func(w *World) error {
+		return Add(w, entity, cloneValue(&component))
+	})
+}
+
+// apply runs the queued commands in order and empties the buffer. Commands
+// queued while applying run as well.
+func (c *Commands) apply(w *World) []error {
+	var errs []error
+	for {
+		c.mu.Lock()
+		queue := c.queue
+		c.queue = nil
+		c.mu.Unlock()
+		if len(queue) == 0 {
+			return errs
+		}
+		for _, cmd := range queue {
+			if err := cmd(w); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+}
@@ -1,84 +1,355 @@
 package ecs
 
-// ComponentInterface is a marker interface for all component types
-type ComponentInterface interface {
-	IsComponent()
-	GetType() ComponentType
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// ComponentID identifies a component type. IDs are assigned the first time a
+// Go type is used as a component and stay fixed for the life of the process.
+type ComponentID uint32
+
+// componentInfo describes a registered component type
+type componentInfo struct {
+	typ       reflect.Type
+	newColumn func() column
+
+	// Set by RegisterComponent
+	name           string
+	decode         func(dec Decoder, w *World, entity Entity) error
+	decodeResource func(dec Decoder, w *World) error
+
+	// Set for Relation[R] types, see relation.go
+	relation relationInfo
+}
+
+// componentRegistry maps Go types to component IDs
+type componentRegistry struct {
+	ids       sync.Map // reflect.Type -> ComponentID, read on every typed access
+	mu        sync.RWMutex
+	infos     []componentInfo
+	byName    map[string]ComponentID
+	relations []ComponentID // IDs of Relation[R] types, append only
+}
+
+var registry = &componentRegistry{
+	byName: make(map[string]ComponentID),
+}
+
+func (r *componentRegistry) lookup(t reflect.Type) (ComponentID, bool) {
+	id, exists := r.ids.Load(t)
+	if !exists {
+		return 0, false
+	}
+	return id.(ComponentID), true
+}
+
+func (r *componentRegistry) register(t reflect.Type, newColumn func() column) ComponentID {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if id, exists := r.lookup(t); exists {
+		return id
+	}
+	id := ComponentID(len(r.infos))
+	r.infos = append(r.infos, componentInfo{typ: t, newColumn: newColumn})
+	r.ids.Store(t, id)
+	return id
+}
+
+func (r *componentRegistry) info(id ComponentID) componentInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.infos[id]
+}
+
+func (r *componentRegistry) newColumn(id ComponentID) column {
+	return r.info(id).newColumn()
 }
 
-// Component is a marker struct for all component types
-type Component struct{}
+func (r *componentRegistry) name(
+	id ComponentID,
+	name string,
+	decode func(dec Decoder, w *World, entity Entity) error,
+	decodeResource func(dec Decoder, w *World) error,
+) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	info := &r.infos[id]
+	if info.name == name {
+		return
+	}
+	if info.name != "" {
+		panic(fmt.Sprintf("ecs: component %v already registered as %q", info.typ, info.name))
+	}
+	if other, exists := r.byName[name]; exists {
+		panic(fmt.Sprintf("ecs: component name %q already used by %v", name, r.infos[other].typ))
+	}
+	info.name = name
+	info.decode = decode
+	info.decodeResource = decodeResource
+	r.byName[name] = id
+}
 
-func (c *Component) IsComponent() {}
+// relation records that id holds relations, setting how they behave if
+// override is set or the kind is new
+func (r *componentRegistry) relation(id ComponentID, info relationInfo, override bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !slices.Contains(r.relations, id) {
+		r.relations = append(r.relations, id)
+	} else if !override {
+		return
+	}
+	r.infos[id].relation = info
+}
 
-type ComponentType string
+// relationIDs returns the IDs of every relation kind in use
+func (r *componentRegistry) relationIDs() []ComponentID {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.relations
+}
+
+func (r *componentRegistry) lookupName(name string) (componentInfo, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	id, exists := r.byName[name]
+	if !exists {
+		return componentInfo{}, false
+	}
+	return r.infos[id], true
+}
+
+// ID returns the component ID for the Go type T
+func ID[T any]() ComponentID {
+	t := reflect.TypeFor[T]()
+	if id, exists := registry.lookup(t); exists {
+		return id
+	}
+	return registry.register(t, newTypedColumn[T])
+}
+
+// RegisterComponent gives the component or resource type T a stable name,
+// which is how it is identified in snapshots. Like gob.Register it is meant
+// to be called during initialization, and it panics if T or name is already
+// registered differently.
+func RegisterComponent[T any](name string) {
+	registry.name(ID[T](), name, decodeComponent[T], decodeResource[T])
+}
 
-// ComponentManager handles storage and retrieval of components
+// String returns the registered name of the component, or its Go type name
+func (id ComponentID) String() string {
+	info := registry.info(id)
+	if info.name != "" {
+		return info.name
+	}
+	return info.typ.String()
+}
+
+// ErrEntityNotAlive is returned when operating on an entity that was never
+// created or has since been removed
+var ErrEntityNotAlive = errors.New("ecs: entity is not alive")
+
+// record locates an entity's components
+type record struct {
+	arch *archetype // nil when the entity has no components
+	row  int
+}
+
+// ComponentManager handles storage and retrieval of components. Components
+// are grouped into archetypes, one per distinct set of component types, with
+// a contiguous array per component type inside each archetype.
 type ComponentManager struct {
-	components map[ComponentType]map[Entity]ComponentInterface
+	entities       *EntityManager
+	archetypes     []*archetype
+	archetypeIndex map[string]*archetype
+	records        []record // indexed by entity index
+	version        uint64   // bumped on every structural change, used to invalidate queries
+	frozen         bool     // set while systems run concurrently
+
+	// Change detection, see change.go and hooks.go
+	tick          atomic.Uint64
+	removed       []removal
+	removedBefore []removal // removals from the previous update
+	hooks         []componentHooks
+	observers     []*observer // including those triggering systems
 }
 
-func NewComponentManager() *ComponentManager {
-	return &ComponentManager{
-		components: make(map[ComponentType]map[Entity]ComponentInterface),
+// checkNotFrozen panics if a structural change is attempted while systems
+// run concurrently. Such a change could move components other systems are
+// using at that moment.
+func checkNotFrozen(frozen bool) {
+	if frozen {
+		panic("ecs: entities and components cannot be added or removed directly by " +
+			"systems that run concurrently; queue the change with World.Commands")
 	}
 }
 
-func (cm *ComponentManager) RegisterComponentType(componentType ComponentType) {
-	if _, exists := cm.components[componentType]; !exists {
-		cm.components[componentType] = make(map[Entity]ComponentInterface)
+func NewComponentManager(entities *EntityManager) *ComponentManager {
+	cm := &ComponentManager{
+		entities:       entities,
+		archetypeIndex: make(map[string]*archetype),
 	}
+	// Start after zero so a reader that has never looked sees everything
+	cm.tick.Store(1)
+	return cm
 }
 
-func (cm *ComponentManager) AddComponent(
-	entity Entity,
-	componentType ComponentType,
-	component ComponentInterface,
-) {
-	if _, exists := cm.components[componentType]; !exists {
-		cm.RegisterComponentType(componentType)
+// record returns the storage record for a live entity, growing the record
+// table if needed
+func (cm *ComponentManager) record(entity Entity) (*record, error) {
+	if !cm.entities.IsAlive(entity) {
+		return nil, fmt.Errorf("%w: %v", ErrEntityNotAlive, entity)
+	}
+	index := int(entity.Index())
+	for len(cm.records) <= index {
+		cm.records = append(cm.records, record{row: -1})
 	}
-	cm.components[componentType][entity] = component
+	return &cm.records[index], nil
 }
 
-func (cm *ComponentManager) RemoveComponent(entity Entity, componentType ComponentType) {
-	if componentMap, exists := cm.components[componentType]; exists {
-		delete(componentMap, entity)
+// lookup returns the entity's archetype and row, or nil if it has no
+// components or is not alive
+func (cm *ComponentManager) lookup(entity Entity) (*archetype, int) {
+	index := int(entity.Index())
+	if index >= len(cm.records) || !cm.entities.IsAlive(entity) {
+		return nil, -1
 	}
+	rec := cm.records[index]
+	return rec.arch, rec.row
 }
 
-func (cm *ComponentManager) GetComponent(
-	entity Entity,
-	componentType ComponentType,
-) (ComponentInterface, bool) {
-	if componentMap, exists := cm.components[componentType]; exists {
-		component, found := componentMap[entity]
-		return component, found
+// GetComponent returns a pointer to the component with the given ID, boxed as any.
+// Prefer Get when the component type is known at compile time.
+func (cm *ComponentManager) GetComponent(entity Entity, id ComponentID) (any, bool) {
+	arch, row := cm.lookup(entity)
+	if arch == nil {
+		return nil, false
 	}
-	return nil, false
+	col := arch.column(id)
+	if col < 0 {
+		return nil, false
+	}
+	return arch.columns[col].get(row), true
 }
 
-func (cm *ComponentManager) HasComponent(entity Entity, componentType ComponentType) bool {
-	if componentMap, exists := cm.components[componentType]; exists {
-		_, found := componentMap[entity]
-		return found
+func (cm *ComponentManager) RemoveComponent(entity Entity, id ComponentID) {
+	arch, row := cm.lookup(entity)
+	if arch == nil || !arch.has(id) {
+		return
 	}
-	return false
+	checkNotFrozen(cm.frozen)
+	cm.recordRemoval(entity, arch, row, id)
+	cm.moveEntity(entity, cm.archetypeWithout(arch, id))
+	cm.version++
+}
+
+func (cm *ComponentManager) HasComponent(entity Entity, id ComponentID) bool {
+	arch, _ := cm.lookup(entity)
+	return arch != nil && arch.has(id)
 }
 
-func (cm *ComponentManager) GetAllEntitiesWithComponent(componentType ComponentType) []Entity {
-	if componentMap, exists := cm.components[componentType]; exists {
-		entities := make([]Entity, 0, len(componentMap))
-		for e := range componentMap {
-			entities = append(entities, e)
+// GetAllEntitiesWithComponent returns the entities that have the component, in ascending ID order
+func (cm *ComponentManager) GetAllEntitiesWithComponent(id ComponentID) []Entity {
+	entities := []Entity{}
+	for _, arch := range cm.archetypes {
+		if arch.has(id) {
+			entities = append(entities, arch.entities...)
 		}
-		return entities
 	}
-	return []Entity{}
+	slices.Sort(entities)
+	return entities
 }
 
+// RemoveAllComponents detaches every component from entity. Removing an
+// entity through World.RemoveEntity does this for you.
 func (cm *ComponentManager) RemoveAllComponents(entity Entity) {
-	for _, componentMap := range cm.components {
-		delete(componentMap, entity)
+	arch, row := cm.lookup(entity)
+	if arch == nil {
+		return
+	}
+	checkNotFrozen(cm.frozen)
+	for _, id := range arch.ids {
+		cm.recordRemoval(entity, arch, row, id)
 	}
+	cm.moveEntity(entity, nil)
+	cm.version++
+}
+
+// typedColumnOf returns the column for T in arch, or nil if arch lacks T
+func typedColumnOf[T any](arch *archetype, id ComponentID) *typedColumn[T] {
+	col := arch.column(id)
+	if col < 0 {
+		return nil
+	}
+	return arch.columns[col].(*typedColumn[T])
+}
+
+// Add attaches component to entity, replacing any existing component of
+// type T. Replacing marks the component as changed. It returns
+// ErrEntityNotAlive if the entity has been removed.
+func Add[T any](w *World, entity Entity, component T) error {
+	cm := w.ComponentManager
+	id := ID[T]()
+	rec, err := cm.record(entity)
+	if err != nil {
+		return err
+	}
+	if rec.arch != nil {
+		if col := rec.arch.column(id); col >= 0 {
+			rec.arch.columns[col].(*typedColumn[T]).data[rec.row] = component
+			rec.arch.ticks[col][rec.row].changed = cm.now()
+			return nil
+		}
+	}
+
+	checkNotFrozen(cm.frozen)
+	dst := cm.archetypeWith(rec.arch, id)
+	row := cm.moveEntity(entity, dst)
+	col := dst.column(id)
+	typed := dst.columns[col].(*typedColumn[T])
+	typed.data = append(typed.data, component)
+	now := cm.now()
+	dst.ticks[col] = append(dst.ticks[col], componentTicks{added: now, changed: now})
+	cm.version++
+	cm.runHooks(onAdd, entity, dst, row, id)
+	return nil
+}
+
+// Get returns a pointer to the entity's component of type T. The pointer
+// refers to the component's storage and is only valid until the next
+// structural change (component added or removed, entity removed).
+func Get[T any](w *World, entity Entity) (*T, bool) {
+	arch, row := w.ComponentManager.lookup(entity)
+	if arch == nil {
+		return nil, false
+	}
+	col := typedColumnOf[T](arch, ID[T]())
+	if col == nil {
+		return nil, false
+	}
+	return &col.data[row], true
+}
+
+// GetMut is like Get but also marks the component as changed
+func GetMut[T any](w *World, entity Entity) (*T, bool) {
+	component, exists := Get[T](w, entity)
+	if exists {
+		MarkChanged[T](w, entity)
+	}
+	return component, exists
+}
+
+// Has reports whether entity has a component of type T
+func Has[T any](w *World, entity Entity) bool {
+	return w.ComponentManager.HasComponent(entity, ID[T]())
+}
+
+// Remove detaches the entity's component of type T, if any
+func Remove[T any](w *World, entity Entity) {
+	w.ComponentManager.RemoveComponent(entity, ID[T]())
 }
@@ -1,41 +1,158 @@
 package ecs
 
-// Entity is just an identifier for game objects
-type Entity int
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+)
 
-// EntityManager handles entity creation and removal
+// Entity is just an identifier for game objects. The high 32 bits hold the
+// entity's index and the low 32 bits a generation that changes every time the
+// index is recycled, so handles to removed entities can be told apart from
+// the entity that reuses their slot. Ordering entities numerically orders
+// them by index.
+type Entity uint64
+
+// NoEntity is the zero Entity, never returned by CreateEntity
+const NoEntity Entity = 0
+
+func newEntity(index, generation uint32) Entity {
+	return Entity(index)<<32 | Entity(generation)
+}
+
+// Index returns the entity's slot index
+func (e Entity) Index() uint32 {
+	return uint32(e >> 32)
+}
+
+// Generation returns how many times the entity's slot had been recycled when
+// the entity was created
+func (e Entity) Generation() uint32 {
+	return uint32(e)
+}
+
+func (e Entity) String() string {
+	return fmt.Sprintf("%dv%d", e.Index(), e.Generation())
+}
+
+// LogValue makes slog show entities the same way as String
+func (e Entity) LogValue() slog.Value {
+	return slog.StringValue(e.String())
+}
+
+// entitySlot tracks one entity index
+type entitySlot struct {
+	generation uint32
+	alive      bool
+	name       string // see World.SetName
+}
+
+// EntityManager handles entity creation and removal. Removed indices are
+// recycled through a free list with their generation bumped.
 type EntityManager struct {
-	nextEntityID Entity
-	entities     map[Entity]struct{}
+	slots   []entitySlot // indexed by entity index, slot 0 is reserved for NoEntity
+	count   int
+	version uint64 // bumped whenever entities are created or removed
+	frozen  bool   // set while systems run concurrently
+	names   map[string]Entity
+
+	// Entities are handed out from the free list, then from nextIndex, which
+	// may run ahead of slots while entities reserved by Commands are pending.
+	// Both are guarded by reserveMu, since Commands reserve entities while
+	// systems run concurrently.
+	reserveMu sync.Mutex
+	free      []uint32
+	nextIndex uint32
 }
 
 func NewEntityManager() *EntityManager {
 	return &EntityManager{
-		nextEntityID: 1,
-		entities:     make(map[Entity]struct{}),
+		slots:     make([]entitySlot, 1),
+		nextIndex: 1,
+		names:     make(map[string]Entity),
 	}
 }
 
 func (em *EntityManager) CreateEntity() Entity {
-	e := em.nextEntityID
-	em.entities[e] = struct{}{}
-	em.nextEntityID++
-	return e
+	checkNotFrozen(em.frozen)
+	entity := em.reserve()
+	em.activate(entity)
+	return entity
 }
 
-func (em *EntityManager) RemoveEntity(entity Entity) {
-	delete(em.entities, entity)
+// reserve hands out a freed index at its current generation, or a fresh
+// index, without touching the slots, so it is safe to call while systems run
+// concurrently
+func (em *EntityManager) reserve() Entity {
+	em.reserveMu.Lock()
+	defer em.reserveMu.Unlock()
+	if n := len(em.free); n > 0 {
+		index := em.free[n-1]
+		em.free = em.free[:n-1]
+		return newEntity(index, em.slots[index].generation)
+	}
+	index := em.nextIndex
+	em.nextIndex++
+	return newEntity(index, 0)
+}
+
+// activate marks a new or reserved entity as alive
+func (em *EntityManager) activate(entity Entity) {
+	index := entity.Index()
+	for uint32(len(em.slots)) <= index {
+		em.slots = append(em.slots, entitySlot{})
+	}
+	em.slots[index].alive = true
+	em.count++
+	em.version++
+}
+
+// remove frees the entity's index for reuse. It leaves the entity's
+// components in place, so entities are removed through World.RemoveEntity,
+// which detaches them first.
+func (em *EntityManager) remove(entity Entity) {
+	checkNotFrozen(em.frozen)
+	if !em.IsAlive(entity) {
+		return
+	}
+	slot := &em.slots[entity.Index()]
+	if slot.name != "" {
+		delete(em.names, slot.name)
+		slot.name = ""
+	}
+	slot.alive = false
+	slot.generation++
+	em.reserveMu.Lock()
+	em.free = append(em.free, entity.Index())
+	em.reserveMu.Unlock()
+	em.count--
+	em.version++
+}
+
+// IsAlive reports whether entity was created and has not been removed since.
+// Handles kept after their entity was removed are never alive, even once
+// the index has been reused.
+func (em *EntityManager) IsAlive(entity Entity) bool {
+	index := entity.Index()
+	if index == 0 || int(index) >= len(em.slots) {
+		return false
+	}
+	slot := em.slots[index]
+	return slot.alive && slot.generation == entity.Generation()
 }
 
-func (em *EntityManager) HasEntity(entity Entity) bool {
-	_, exists := em.entities[entity]
-	return exists
+// Count returns the number of live entities
+func (em *EntityManager) Count() int {
+	return em.count
 }
 
+// GetAllEntities returns every live entity in ascending ID order
 func (em *EntityManager) GetAllEntities() []Entity {
-	entities := make([]Entity, 0, len(em.entities))
-	for e := range em.entities {
-		entities = append(entities, e)
+	entities := make([]Entity, 0, em.count)
+	for index, slot := range em.slots {
+		if slot.alive {
+			entities = append(entities, newEntity(uint32(index), slot.generation))
+		}
 	}
 	return entities
 }
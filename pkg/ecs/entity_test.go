@@ -0,0 +1,46 @@
+package ecs
+
+import "testing"
+
+func TestStaleHandlesDoNotSeeRecycledEntities(t *testing.T) {
+	w := NewWorld(nil)
+	old := w.EntityManager.CreateEntity()
+	if err := Add(w, old, orderA{1}); err != nil {
+		t.Fatal(err)
+	}
+	w.RemoveEntity(old)
+	recycled := w.EntityManager.CreateEntity()
+	if recycled.Index() != old.Index() || recycled == old {
+		t.Fatalf("CreateEntity returned %v after removing %v, want the index recycled", recycled, old)
+	}
+	if w.IsAlive(old) || Has[orderA](w, old) {
+		t.Error("the removed handle still looks alive")
+	}
+	if Has[orderA](w, recycled) {
+		t.Error("the recycled entity inherited the removed entity's component")
+	}
+	if err := Add(w, old, orderA{2}); err == nil {
+		t.Error("adding a component through the removed handle succeeded")
+	}
+	if got := NewQuery(With[orderA]()).Entities(w); len(got) != 0 {
+		t.Errorf("query returned %v, want no entities", got)
+	}
+}
+
+func TestCommandsRecycleEntities(t *testing.T) {
+	w := NewWorld(nil)
+	var last Entity
+	for range 1000 {
+		last = w.Commands().CreateEntity()
+		w.Commands().RemoveEntity(last)
+		if err := w.Update(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if last.Index() != 1 {
+		t.Errorf("last entity created through commands is %v, want index 1 recycled", last)
+	}
+	if e := w.EntityManager.CreateEntity(); e.Index() != 1 {
+		t.Errorf("CreateEntity returned %v, want index 1 recycled", e)
+	}
+}
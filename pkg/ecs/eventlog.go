@@ -0,0 +1,212 @@
+package ecs
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+)
+
+// LogEntry is one event in an event log
+type LogEntry struct {
+	// Update counts the updates run since recording started. Events produced
+	// by an update and the inputs that preceded it share its number.
+	Update uint64 `json:"update"`
+	// Input is set for events emitted or queued outside of World.Update
+	// and outside of event handlers, such as player commands. Replay feeds
+	// these back in.
+	Input bool `json:"input,omitempty"`
+	// Queued is set for inputs passed to QueueEvent rather than Emit
+	Queued bool            `json:"queued,omitempty"`
+	Type   EventType       `json:"type"`
+	Data   json.RawMessage `json:"data"`
+}
+
+// EventLog records every event a world emits or queues, see World.SetEventLog
+type EventLog struct {
+	write func(entry LogEntry) error
+	start uint64 // the world's update count when recording started
+}
+
+// NewEventLog returns a log writing one JSON object per line to out
+func NewEventLog(out io.Writer) *EventLog {
+	enc := json.NewEncoder(out)
+	return &EventLog{write: func(entry LogEntry) error {
+		return enc.Encode(entry)
+	}}
+}
+
+// SetEventLog starts recording events to log, or stops recording if log is
+// nil. Update numbers in the log count from this call.
+func (w *World) SetEventLog(log *EventLog) {
+	if log != nil {
+		log.start = w.updates
+	}
+	w.eventLog = log
+}
+
+// logEvent appends event to the event log, if any. Callers queueing events
+// hold the event lock, so concurrent systems cannot interleave entries.
+func (w *World) logEvent(event EventInterface, queued bool) {
+	if w.eventLog == nil {
+		return
+	}
+	// Events published by handlers in response to an input are produced
+	// again when the input is replayed
+	input := !w.updating && w.dispatching == 0
+	data, err := json.Marshal(event)
+	if err == nil {
+		err = w.eventLog.write(LogEntry{
+			Update: w.updates - w.eventLog.start,
+			Input:  input,
+			Queued: queued && input,
+			Type:   event.Type(),
+			Data:   data,
+		})
+	}
+	if err != nil {
+		w.Logger.Error("failed to log event", "event", event.Type(), "error", err)
+	}
+}
+
+// ReadEventLog reads back the entries written by an EventLog
+func ReadEventLog(in io.Reader) ([]LogEntry, error) {
+	dec := json.NewDecoder(in)
+	var entries []LogEntry
+	for {
+		var entry LogEntry
+		err := dec.Decode(&entry)
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("ecs: reading event log entry %d: %w", len(entries)+1, err)
+		}
+		entries = append(entries, entry)
+	}
+}
+
+// eventTypes maps event types to the Go types they decode into
+var eventTypes sync.Map // EventType -> reflect.Type
+
+// RegisterEvent makes events of type E decodable from event logs. Like
+// RegisterComponent it is meant to be called during initialization, and it
+// panics if E's event type is already registered to another Go type.
+func RegisterEvent[E EventInterface]() {
+	eventType, t := eventTypeOf[E](), reflect.TypeFor[E]()
+	if other, loaded := eventTypes.LoadOrStore(eventType, t); loaded && other != t {
+		panic(fmt.Sprintf("ecs: event type %q already registered to %v", eventType, other))
+	}
+}
+
+// Event decodes the entry's event. Its type must have been registered with
+// RegisterEvent.
+func (e LogEntry) Event() (EventInterface, error) {
+	t, exists := eventTypes.Load(e.Type)
+	if !exists {
+		return nil, fmt.Errorf("ecs: event type %q is not registered", e.Type)
+	}
+	event := reflect.New(t.(reflect.Type))
+	if err := json.Unmarshal(e.Data, event.Interface()); err != nil {
+		return nil, fmt.Errorf("ecs: decoding %s event: %w", e.Type, err)
+	}
+	return event.Elem().Interface().(EventInterface), nil
+}
+
+// ErrReplayMismatch is returned when a replayed world produces different
+// events than the ones recorded
+var ErrReplayMismatch = errors.New("ecs: replay does not match the log")
+
+// Replay re-runs a recorded session on w, which must be set up exactly as
+// the recorded world was when recording started. Before each update the
+// recorded inputs are fed back in, and afterwards the events the update
+// produced are compared with the recorded ones. It returns the number of
+// updates replayed, and an error wrapping ErrReplayMismatch at the first
+// difference. Errors returned by systems only stop the replay if they halt
+// the world.
+//
+// Updates are run with step, which must update the world the way the
+// recording did, such as the Step of a Runner for w so the Time resource
+// advances as it did. A nil step runs World.Update.
+func Replay(w *World, entries []LogEntry, step func() error) (int, error) {
+	if step == nil {
+		step = w.Update
+	}
+	var produced []LogEntry
+	previous := w.eventLog
+	w.SetEventLog(&EventLog{write: func(entry LogEntry) error {
+		if !entry.Input {
+			produced = append(produced, entry)
+		}
+		return nil
+	}})
+	defer w.SetEventLog(previous)
+
+	updates := 0
+	for len(entries) > 0 {
+		update := uint64(updates)
+		if entries[0].Update < update {
+			return updates, fmt.Errorf(
+				"ecs: event log entries for update %d are out of order", entries[0].Update,
+			)
+		}
+		var expected []LogEntry
+		produced = produced[:0]
+		for len(entries) > 0 && entries[0].Update == update {
+			entry := entries[0]
+			entries = entries[1:]
+			if !entry.Input {
+				expected = append(expected, entry)
+				continue
+			}
+			event, err := entry.Event()
+			if err != nil {
+				return updates, err
+			}
+			if entry.Queued {
+				w.QueueEvent(event)
+			} else {
+				w.Emit(event)
+			}
+		}
+
+		if err := step(); errors.Is(err, ErrWorldHalted) {
+			return updates, err
+		}
+		updates++
+		if err := compareEntries(update, expected, produced); err != nil {
+			return updates, err
+		}
+	}
+	return updates, nil
+}
+
+func compareEntries(update uint64, expected, produced []LogEntry) error {
+	for i := range max(len(expected), len(produced)) {
+		if i >= len(expected) {
+			return fmt.Errorf("%w: update %d produced unexpected %s event %s",
+				ErrReplayMismatch, update, produced[i].Type, produced[i].Data)
+		}
+		if i >= len(produced) {
+			return fmt.Errorf("%w: update %d did not produce %s event %s",
+				ErrReplayMismatch, update, expected[i].Type, expected[i].Data)
+		}
+		if expected[i].Type != produced[i].Type || !sameJSON(expected[i].Data, produced[i].Data) {
+			return fmt.Errorf("%w: update %d produced %s event %s, recorded %s event %s",
+				ErrReplayMismatch, update, produced[i].Type, produced[i].Data, expected[i].Type, expected[i].Data)
+		}
+	}
+	return nil
+}
+
+// sameJSON compares two JSON values ignoring insignificant whitespace
+func sameJSON(a, b json.RawMessage) bool {
+	var ca, cb bytes.Buffer
+	if json.Compact(&ca, a) != nil || json.Compact(&cb, b) != nil {
+		return bytes.Equal(a, b)
+	}
+	return bytes.Equal(ca.Bytes(), cb.Bytes())
+}
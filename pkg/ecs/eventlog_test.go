@@ -0,0 +1,116 @@
+package ecs
+
+import (
+	"bytes"
+	"testing"
+)
+
+type logInEvent struct{ N int }
+
+func (logInEvent) Type() EventType { return "log_in" }
+func (logInEvent) Entity() Entity  { return NoEntity }
+func (e logInEvent) Data() any     { return e }
+
+type logOutEvent struct{ N int }
+
+func (logOutEvent) Type() EventType { return "log_out" }
+func (logOutEvent) Entity() Entity  { return NoEntity }
+func (e logOutEvent) Data() any     { return e }
+
+func init() {
+	RegisterEvent[logInEvent]()
+	RegisterEvent[logOutEvent]()
+}
+
+// newLogWorld returns a world whose "in" handler publishes an "out" event,
+// and a pointer to the number of "out" events handled
+func newLogWorld() (*World, *int) {
+	w := NewWorld(nil)
+	handled := new(int)
+	Subscribe(w, func(e logInEvent) { Publish(w, logOutEvent{N: e.N * 10}) })
+	Subscribe(w, func(logOutEvent) { *handled++ })
+	return w, handled
+}
+
+func TestEventLogOnlyMarksTopLevelEventsAsInputs(t *testing.T) {
+	w, _ := newLogWorld()
+	var buf bytes.Buffer
+	w.SetEventLog(NewEventLog(&buf))
+	w.Emit(logInEvent{N: 1})
+	if err := w.Update(); err != nil {
+		t.Fatal(err)
+	}
+	w.QueueEvent(logInEvent{N: 2})
+	if err := w.Update(); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := ReadEventLog(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 4 {
+		t.Fatalf("logged %d events, want 4: %+v", len(entries), entries)
+	}
+	for _, entry := range entries {
+		if want := entry.Type == "log_in"; entry.Input != want {
+			t.Errorf("%s event logged with Input %v, want %v", entry.Type, entry.Input, want)
+		}
+	}
+
+	replayed, handled := newLogWorld()
+	updates, err := Replay(replayed, entries, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updates != 2 {
+		t.Errorf("replayed %d updates, want 2", updates)
+	}
+	if *handled != 2 {
+		t.Errorf("replay handled %d out events, want 2", *handled)
+	}
+
+	// A handler publishing something else is reported
+	mismatched := NewWorld(nil)
+	Subscribe(mismatched, func(e logInEvent) { Publish(mismatched, logOutEvent{N: e.N}) })
+	if _, err := Replay(mismatched, entries, nil); err == nil {
+		t.Error("replay with a different handler did not report a mismatch")
+	}
+}
+
+func TestReplayStepsLikeTheRecording(t *testing.T) {
+	// The system publishes the simulated time, which only a runner advances
+	newTimedWorld := func() (*World, *Runner) {
+		w := NewWorld(nil)
+		w.AddSystem(funcSystem(func(w *World) error {
+			clock, err := Resource[Time](w)
+			if err != nil {
+				return err
+			}
+			Publish(w, logOutEvent{N: int(clock.Elapsed / DefaultStep)})
+			return nil
+		}))
+		return w, NewRunner(w)
+	}
+
+	recorded, runner := newTimedWorld()
+	var buf bytes.Buffer
+	recorded.SetEventLog(NewEventLog(&buf))
+	for range 3 {
+		if err := runner.Step(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries, err := ReadEventLog(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	replayed, runner := newTimedWorld()
+	if updates, err := Replay(replayed, entries, runner.Step); err != nil || updates != 3 {
+		t.Fatalf("replaying through the runner: %d updates, %v", updates, err)
+	}
+	if _, err := Replay(replayed, entries, nil); err == nil {
+		t.Error("replaying without the runner did not report a mismatch")
+	}
+}
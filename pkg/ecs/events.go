@@ -0,0 +1,224 @@
+package ecs
+
+import (
+	"context"
+	"log/slog"
+	"reflect"
+	"sync"
+)
+
+// Simple event system for communication between ECS and external systems
+type EventType string
+
+// AllEvents subscribes a handler to every event type
+const AllEvents EventType = "*"
+
+type EventInterface interface {
+	Type() EventType
+	Entity() Entity
+	Data() any
+}
+
+// maxEventRounds bounds how often queued events may cascade into further
+// events within one update
+const maxEventRounds = 100
+
+// HandlerID identifies a registered event handler
+type HandlerID uint64
+
+// HandlerOption configures an event handler
+type HandlerOption func(h *eventHandler)
+
+// Priority sets the order in which handlers of an event run. Handlers with
+// a higher priority run first, and handlers of equal priority run in the
+// order they were registered. The default priority is 0.
+func Priority(priority int) HandlerOption {
+	return func(h *eventHandler) {
+		h.priority = priority
+	}
+}
+
+type eventHandler struct {
+	id       HandlerID
+	priority int
+	handle   func(EventInterface)
+}
+
+// before reports whether h runs before other
+func (h eventHandler) before(other eventHandler) bool {
+	if h.priority != other.priority {
+		return h.priority > other.priority
+	}
+	return h.id < other.id
+}
+
+// eventBus queues events and dispatches them to handlers
+type eventBus struct {
+	mu       sync.Mutex // guards queue, systems may queue events concurrently
+	queue    []EventInterface
+	handlers map[EventType][]eventHandler // in run order, replaced rather than modified
+	nextID   HandlerID
+}
+
+func newEventBus() eventBus {
+	return eventBus{handlers: make(map[EventType][]eventHandler)}
+}
+
+// RegisterEventHandler subscribes handler to events of the given type, or
+// to every event when eventType is AllEvents. The returned ID can be passed
+// to UnregisterEventHandler.
+func (w *World) RegisterEventHandler(
+	eventType EventType,
+	handler func(EventInterface),
+	opts ...HandlerOption,
+) HandlerID {
+	bus := &w.events
+	bus.nextID++
+	h := eventHandler{id: bus.nextID, handle: handler}
+	for _, opt := range opts {
+		opt(&h)
+	}
+
+	// Copy rather than insert in place so dispatches in progress keep
+	// iterating the handlers they started with
+	existing := bus.handlers[eventType]
+	handlers := make([]eventHandler, 0, len(existing)+1)
+	inserted := false
+	for _, other := range existing {
+		if !inserted && h.before(other) {
+			handlers = append(handlers, h)
+			inserted = true
+		}
+		handlers = append(handlers, other)
+	}
+	if !inserted {
+		handlers = append(handlers, h)
+	}
+	bus.handlers[eventType] = handlers
+	return h.id
+}
+
+// UnregisterEventHandler removes a handler and reports whether it was
+// registered. Dispatches already in progress still run it.
+func (w *World) UnregisterEventHandler(id HandlerID) bool {
+	bus := &w.events
+	for eventType, existing := range bus.handlers {
+		for i, h := range existing {
+			if h.id != id {
+				continue
+			}
+			handlers := make([]eventHandler, 0, len(existing)-1)
+			handlers = append(handlers, existing[:i]...)
+			handlers = append(handlers, existing[i+1:]...)
+			if len(handlers) == 0 {
+				delete(bus.handlers, eventType)
+			} else {
+				bus.handlers[eventType] = handlers
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// QueueEvent queues an event for processing at the end of the update. It is
+// safe to call from systems running concurrently, but other goroutines must
+// go through Runner.Send while the world updates.
+func (w *World) QueueEvent(event EventInterface) {
+	w.events.mu.Lock()
+	defer w.events.mu.Unlock()
+	w.logEvent(event, true)
+	w.countEvent()
+	w.events.queue = append(w.events.queue, event)
+}
+
+// Emit dispatches an event to its handlers right away, before returning.
+// Unlike QueueEvent it must not be called from systems running concurrently.
+func (w *World) Emit(event EventInterface) {
+	w.logEvent(event, false)
+	w.countEvent()
+	w.dispatch(event)
+}
+
+// dispatch runs the handlers of event in priority order
+func (w *World) dispatch(event EventInterface) {
+	w.dispatching++
+	defer func() { w.dispatching-- }()
+	if w.Logger.Enabled(context.Background(), slog.LevelDebug) {
+		w.Logger.Debug("dispatching event", "event", event.Type(), "entity", w.Label(event.Entity()))
+	}
+
+	specific := w.events.handlers[event.Type()]
+	wildcard := w.events.handlers[AllEvents]
+	if event.Type() == AllEvents {
+		wildcard = nil
+	}
+
+	// Merge the two lists, both already in run order
+	i, j := 0, 0
+	for i < len(specific) || j < len(wildcard) {
+		if j == len(wildcard) || (i < len(specific) && specific[i].before(wildcard[j])) {
+			specific[i].handle(event)
+			i++
+		} else {
+			wildcard[j].handle(event)
+			j++
+		}
+	}
+}
+
+// eventTypeOf returns the event type reported by a zero E. When E is a
+// pointer, Type is called on a pointer to a zero value rather than on nil,
+// which panics for methods with value receivers.
+func eventTypeOf[E EventInterface]() EventType {
+	if t := reflect.TypeFor[E](); t.Kind() == reflect.Pointer {
+		return reflect.New(t.Elem()).Interface().(E).Type()
+	}
+	var zero E
+	return zero.Type()
+}
+
+// Subscribe registers handler for events of type E, which receives them as
+// E rather than EventInterface. The event type subscribed to is the one
+// reported by the zero value of E, or by a pointer to one if E is a pointer,
+// so E's Type method must not depend on its fields.
+func Subscribe[E EventInterface](w *World, handler func(E), opts ...HandlerOption) HandlerID {
+	return w.RegisterEventHandler(eventTypeOf[E](), func(event EventInterface) {
+		// Another Go type may share the event type, skip those
+		if e, ok := event.(E); ok {
+			handler(e)
+		}
+	}, opts...)
+}
+
+// Publish queues event for the handlers subscribed to E. Like QueueEvent it
+// is safe to call from systems running concurrently.
+func Publish[E EventInterface](w *World, event E) {
+	w.QueueEvent(event)
+}
+
+// processEvents dispatches queued events in order. Events queued by handlers
+// are processed in further rounds until none are left, up to maxEventRounds,
+// after which the remaining events are dropped.
+func (w *World) processEvents() {
+	for range maxEventRounds {
+		w.events.mu.Lock()
+		queue := w.events.queue
+		w.events.queue = nil
+		w.events.mu.Unlock()
+		if len(queue) == 0 {
+			return
+		}
+		for _, event := range queue {
+			w.dispatch(event)
+		}
+	}
+
+	w.events.mu.Lock()
+	dropped := len(w.events.queue)
+	w.events.queue = nil
+	w.events.mu.Unlock()
+	if dropped > 0 {
+		w.Logger.Warn("dropped events still cascading", "events", dropped, "rounds", maxEventRounds)
+	}
+}
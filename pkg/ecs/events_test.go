@@ -0,0 +1,149 @@
+package ecs
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+)
+
+type busEvent struct{ N int }
+
+func (busEvent) Type() EventType { return "bus" }
+func (busEvent) Entity() Entity  { return NoEntity }
+func (e busEvent) Data() any     { return e }
+
+type otherBusEvent struct{}
+
+func (otherBusEvent) Type() EventType { return "other_bus" }
+func (otherBusEvent) Entity() Entity  { return NoEntity }
+func (e otherBusEvent) Data() any     { return e }
+
+func TestQueuedEventsCascadeInRounds(t *testing.T) {
+	w := NewWorld(nil)
+	var handled []int
+	Subscribe(w, func(e busEvent) {
+		handled = append(handled, e.N)
+		if e.N < 20 {
+			Publish(w, busEvent{e.N + 10})
+		}
+	})
+	w.QueueEvent(busEvent{1})
+	w.QueueEvent(busEvent{2})
+	if err := w.Update(); err != nil {
+		t.Fatal(err)
+	}
+	// Each round handles the events queued by the previous one, in order
+	if want := []int{1, 2, 11, 12, 21, 22}; !slices.Equal(handled, want) {
+		t.Errorf("handled %v, want %v", handled, want)
+	}
+}
+
+func TestEndlessEventCascadeIsDropped(t *testing.T) {
+	var logs bytes.Buffer
+	w := NewWorld(slog.New(slog.NewTextHandler(&logs, nil)))
+	handled := 0
+	id := Subscribe(w, func(e busEvent) {
+		handled++
+		Publish(w, e)
+	})
+	w.QueueEvent(busEvent{})
+	if err := w.Update(); err != nil {
+		t.Fatal(err)
+	}
+	if handled != maxEventRounds {
+		t.Errorf("handled %d events, want one per round, %d", handled, maxEventRounds)
+	}
+	if !strings.Contains(logs.String(), "dropped events still cascading") {
+		t.Errorf("no warning logged about dropped events, logs: %s", logs.String())
+	}
+
+	// The event left over from the last round is gone
+	w.UnregisterEventHandler(id)
+	Subscribe(w, func(busEvent) { handled++ })
+	if err := w.Update(); err != nil {
+		t.Fatal(err)
+	}
+	if handled != maxEventRounds {
+		t.Error("the dropped event was handled in the next update")
+	}
+}
+
+func TestHandlersRunInPriorityOrder(t *testing.T) {
+	w := NewWorld(nil)
+	var order []string
+	handler := func(name string) func(EventInterface) {
+		return func(EventInterface) { order = append(order, name) }
+	}
+	w.RegisterEventHandler("bus", handler("default"))
+	w.RegisterEventHandler("bus", handler("high"), Priority(10))
+	w.RegisterEventHandler("bus", handler("low"), Priority(-5))
+	w.RegisterEventHandler("bus", handler("high again"), Priority(10))
+	w.RegisterEventHandler("bus", handler("default again"))
+	w.Emit(busEvent{})
+
+	want := []string{"high", "high again", "default", "default again", "low"}
+	if !slices.Equal(order, want) {
+		t.Errorf("handlers ran in order %v, want %v", order, want)
+	}
+}
+
+func TestWildcardHandlersMergeByPriority(t *testing.T) {
+	w := NewWorld(nil)
+	var order []string
+	handler := func(name string) func(EventInterface) {
+		return func(event EventInterface) { order = append(order, fmt.Sprintf("%s %s", name, event.Type())) }
+	}
+	w.RegisterEventHandler("bus", handler("specific"))
+	w.RegisterEventHandler(AllEvents, handler("all"))
+	w.RegisterEventHandler(AllEvents, handler("all high"), Priority(5))
+	w.RegisterEventHandler("bus", handler("specific high"), Priority(10))
+	w.Emit(busEvent{})
+	w.Emit(otherBusEvent{})
+
+	want := []string{
+		"specific high bus", "all high bus", "specific bus", "all bus",
+		"all high other_bus", "all other_bus",
+	}
+	if !slices.Equal(order, want) {
+		t.Errorf("handlers ran in order %v, want %v", order, want)
+	}
+}
+
+func TestUnregisterEventHandler(t *testing.T) {
+	w := NewWorld(nil)
+	var order []string
+	var second HandlerID
+	w.RegisterEventHandler("bus", func(EventInterface) {
+		order = append(order, "first")
+		w.UnregisterEventHandler(second)
+	})
+	second = w.RegisterEventHandler("bus", func(EventInterface) { order = append(order, "second") })
+
+	// The dispatch that unregisters the handler still runs it
+	w.Emit(busEvent{})
+	w.Emit(busEvent{})
+	if want := []string{"first", "second", "first"}; !slices.Equal(order, want) {
+		t.Errorf("handlers ran %v, want %v", order, want)
+	}
+	if w.UnregisterEventHandler(second) {
+		t.Error("unregistering a handler twice reported it registered")
+	}
+	if w.UnregisterEventHandler(HandlerID(999)) {
+		t.Error("unregistering an unknown handler reported it registered")
+	}
+}
+
+func TestSubscribePointerEvents(t *testing.T) {
+	w := NewWorld(nil)
+	var values, pointers []int
+	Subscribe(w, func(e busEvent) { values = append(values, e.N) })
+	Subscribe(w, func(e *busEvent) { pointers = append(pointers, e.N) })
+	w.Emit(busEvent{1})
+	w.Emit(&busEvent{2})
+	if !slices.Equal(values, []int{1}) || !slices.Equal(pointers, []int{2}) {
+		t.Errorf("value handler got %v, pointer handler got %v, want [1] and [2]", values, pointers)
+	}
+}
@@ -0,0 +1,50 @@
+package ecs
+
+type hookKind int
+
+const (
+	onAdd hookKind = iota
+	onRemove
+)
+
+// componentHooks holds the callbacks registered for one component type,
+// indexed by hookKind
+type componentHooks [2][]func(entity Entity, component any)
+
+// OnAdd registers hook to run whenever a component of type T is attached to
+// an entity that did not have one. Replacing an existing component counts as
+// a change, not an add. The hook runs as soon as the component is stored, and
+// must make structural changes through World.Commands.
+func OnAdd[T any](w *World, hook func(entity Entity, component *T)) {
+	w.ComponentManager.addHook(onAdd, ID[T](), func(entity Entity, component any) {
+		hook(entity, component.(*T))
+	})
+}
+
+// OnRemove registers hook to run whenever a component of type T is detached
+// from an entity, including when the entity is removed. The hook runs just
+// before the component is dropped, and must make structural changes through
+// World.Commands.
+func OnRemove[T any](w *World, hook func(entity Entity, component *T)) {
+	w.ComponentManager.addHook(onRemove, ID[T](), func(entity Entity, component any) {
+		hook(entity, component.(*T))
+	})
+}
+
+func (cm *ComponentManager) addHook(kind hookKind, id ComponentID, hook func(Entity, any)) {
+	for len(cm.hooks) <= int(id) {
+		cm.hooks = append(cm.hooks, componentHooks{})
+	}
+	cm.hooks[id][kind] = append(cm.hooks[id][kind], hook)
+}
+
+// runHooks calls the hooks of the given kind for the component at row of arch
+func (cm *ComponentManager) runHooks(kind hookKind, entity Entity, arch *archetype, row int, id ComponentID) {
+	if int(id) >= len(cm.hooks) || len(cm.hooks[id][kind]) == 0 {
+		return
+	}
+	component := arch.columns[arch.column(id)].get(row)
+	for _, hook := range cm.hooks[id][kind] {
+		hook(entity, component)
+	}
+}
@@ -0,0 +1,166 @@
+package ecs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"runtime/pprof"
+	"text/tabwriter"
+	"time"
+)
+
+// Timing accumulates the durations of something run repeatedly
+type Timing struct {
+	Runs  uint64
+	Total time.Duration
+	Max   time.Duration
+	Last  time.Duration
+}
+
+func (t *Timing) record(d time.Duration) {
+	t.Runs++
+	t.Total += d
+	t.Last = d
+	t.Max = max(t.Max, d)
+}
+
+// Average returns the mean duration of a run
+func (t Timing) Average() time.Duration {
+	if t.Runs == 0 {
+		return 0
+	}
+	return t.Total / time.Duration(t.Runs)
+}
+
+// SystemMetrics describes how often a system ran and how long it took.
+// Updates where the system was skipped are not counted.
+type SystemMetrics struct {
+	Name  string
+	Stage Stage
+	Timing
+}
+
+// Metrics is a snapshot of a world's instrumentation, see EnableMetrics
+type Metrics struct {
+	// Update covers whole calls to World.Update
+	Update Timing
+	// Systems in registration order
+	Systems []SystemMetrics
+	// Events counts the events emitted or queued, in total, during the last
+	// update and during the busiest update
+	Events          uint64
+	EventsLast      uint64
+	EventsMaxUpdate uint64
+}
+
+// worldMetrics is the world's instrumentation state
+type worldMetrics struct {
+	enabled     bool
+	pprofLabels bool
+	update      Timing
+	events      uint64
+	eventsLast  uint64
+	eventsMax   uint64
+	eventsStart uint64 // events count when the last update started
+}
+
+// EnableMetrics turns recording of update and system timings and event
+// counts on or off. Recording is off by default.
+func (w *World) EnableMetrics(enabled bool) {
+	w.metrics.enabled = enabled
+}
+
+// EnablePprofLabels runs every system with a pprof "ecs_system" label set
+// to its name, so CPU profiles can be broken down by system
+func (w *World) EnablePprofLabels(enabled bool) {
+	w.metrics.pprofLabels = enabled
+}
+
+// Metrics returns the instrumentation recorded so far
+func (w *World) Metrics() Metrics {
+	m := Metrics{
+		Update:          w.metrics.update,
+		Events:          w.metrics.events,
+		EventsLast:      w.metrics.eventsLast,
+		EventsMaxUpdate: w.metrics.eventsMax,
+	}
+	for _, entry := range w.scheduler.entries {
+		m.Systems = append(m.Systems, SystemMetrics{
+			Name:   entry.name,
+			Stage:  entry.stage,
+			Timing: entry.timing,
+		})
+	}
+	return m
+}
+
+// ResetMetrics clears the recorded instrumentation
+func (w *World) ResetMetrics() {
+	w.metrics.update = Timing{}
+	w.metrics.events, w.metrics.eventsLast, w.metrics.eventsMax = 0, 0, 0
+	for _, entry := range w.scheduler.entries {
+		entry.timing = Timing{}
+	}
+}
+
+// WriteTable writes the metrics as a human readable table
+func (m Metrics) WriteTable(out io.Writer) error {
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "system\tstage\truns\ttotal\taverage\tmax\t")
+	row := func(name, stage string, t Timing) {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%v\t%v\t%v\t\n", name, stage, t.Runs, t.Total, t.Average(), t.Max)
+	}
+	for _, s := range m.Systems {
+		row(s.Name, s.Stage.String(), s.Timing)
+	}
+	row("(update)", "", m.Update)
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(out, "events: %d total, %d last update, %d in the busiest update\n",
+		m.Events, m.EventsLast, m.EventsMaxUpdate)
+	return err
+}
+
+// instrument runs fn for the system as configured by EnableMetrics and
+// EnablePprofLabels
+func (w *World) instrument(entry *systemEntry, fn func()) {
+	if w.metrics.pprofLabels {
+		inner := fn
+		fn = func() {
+			pprof.Do(context.Background(), pprof.Labels("ecs_system", entry.name), func(context.Context) {
+				inner()
+			})
+		}
+	}
+	if !w.metrics.enabled {
+		fn()
+		return
+	}
+	start := time.Now()
+	fn()
+	entry.timing.record(time.Since(start))
+}
+
+// countEvent counts an emitted or queued event. Callers queueing events
+// hold the event lock.
+func (w *World) countEvent() {
+	if w.metrics.enabled {
+		w.metrics.events++
+	}
+}
+
+// beginUpdate and endUpdate bracket an update for the metrics
+func (w *World) beginUpdate() time.Time {
+	w.metrics.eventsStart = w.metrics.events
+	return time.Now()
+}
+
+func (w *World) endUpdate(start time.Time) {
+	if !w.metrics.enabled {
+		return
+	}
+	w.metrics.update.record(time.Since(start))
+	w.metrics.eventsLast = w.metrics.events - w.metrics.eventsStart
+	w.metrics.eventsMax = max(w.metrics.eventsMax, w.metrics.eventsLast)
+}
@@ -0,0 +1,76 @@
+package ecs
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNameTaken is returned when naming an entity with a name another entity has
+var ErrNameTaken = errors.New("ecs: entity name is already taken")
+
+// setName names entity, or clears its name if name is empty
+func (em *EntityManager) setName(entity Entity, name string) error {
+	checkNotFrozen(em.frozen)
+	if !em.IsAlive(entity) {
+		return fmt.Errorf("%w: %v", ErrEntityNotAlive, entity)
+	}
+	if other, exists := em.names[name]; exists && other != entity {
+		return fmt.Errorf("%w: %q is %v", ErrNameTaken, name, other)
+	}
+	slot := &em.slots[entity.Index()]
+	if slot.name != "" {
+		delete(em.names, slot.name)
+	}
+	slot.name = name
+	if name != "" {
+		em.names[name] = entity
+	}
+	return nil
+}
+
+// name returns the entity's name, or "" if it has none or is not alive
+func (em *EntityManager) name(entity Entity) string {
+	if !em.IsAlive(entity) {
+		return ""
+	}
+	return em.slots[entity.Index()].name
+}
+
+// label describes the entity by name and handle, for logs and errors
+func (em *EntityManager) label(entity Entity) string {
+	if name := em.name(entity); name != "" {
+		return fmt.Sprintf("%s (%v)", name, entity)
+	}
+	return entity.String()
+}
+
+// SetName gives entity a name, unique within the world, to find it by with
+// Lookup and to show in logs and snapshots. An empty name clears the
+// entity's name. Names are released when their entity is removed.
+func (w *World) SetName(entity Entity, name string) error {
+	return w.EntityManager.setName(entity, name)
+}
+
+// NameOf returns the entity's name, or "" if it has none
+func (w *World) NameOf(entity Entity) string {
+	return w.EntityManager.name(entity)
+}
+
+// Lookup returns the entity with the given name
+func (w *World) Lookup(name string) (Entity, bool) {
+	entity, exists := w.EntityManager.names[name]
+	return entity, exists
+}
+
+// Label describes entity for logs, such as "board (3v0)", or just "3v0"
+// when it has no name
+func (w *World) Label(entity Entity) string {
+	return w.EntityManager.label(entity)
+}
+
+// SetName queues naming entity, see World.SetName
+func (c *Commands) SetName(entity Entity, name string) {
+	c.push(func(w *World) error {
+		return w.SetName(entity, name)
+	})
+}
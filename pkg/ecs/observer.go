@@ -0,0 +1,150 @@
+package ecs
+
+// maxObserverRounds bounds how often observers are re-run when their
+// callbacks keep changing which entities match
+const maxObserverRounds = 100
+
+// ObserverFunc is called for an entity that entered or left an observed query
+type ObserverFunc func(w *World, entity Entity)
+
+// transition records an entity entering or leaving an observed query
+type transition struct {
+	entity  Entity
+	entered bool
+}
+
+// observer collects the entities entering and leaving a query as components
+// are added and removed
+type observer struct {
+	filter  Filter
+	pending []transition
+	onEnter ObserverFunc
+	onExit  ObserverFunc
+}
+
+func newObserver(q *Query, onEnter, onExit ObserverFunc) *observer {
+	// Observers only follow structural changes, so drop any change and
+	// relation terms
+	filter := q.filter
+	filter.added, filter.changed, filter.related = nil, nil, nil
+	return &observer{filter: filter, onEnter: onEnter, onExit: onExit}
+}
+
+// moved records whether an entity moving between archetypes entered or left
+// the query. Entities without components never match.
+func (o *observer) moved(entity Entity, src, dst *archetype) {
+	before := src != nil && o.filter.matchesArchetype(src)
+	after := dst != nil && o.filter.matchesArchetype(dst)
+	if before != after {
+		o.pending = append(o.pending, transition{entity: entity, entered: after})
+	}
+}
+
+// take returns the transitions recorded since the last call
+func (o *observer) take() []transition {
+	pending := o.pending
+	o.pending = nil
+	return pending
+}
+
+// notify runs the callbacks for the recorded transitions and reports
+// whether there were any
+func (o *observer) notify(w *World) bool {
+	pending := o.take()
+	for _, t := range pending {
+		if t.entered && o.onEnter != nil {
+			o.onEnter(w, t.entity)
+		} else if !t.entered && o.onExit != nil {
+			o.onExit(w, t.entity)
+		}
+	}
+	return len(pending) > 0
+}
+
+func (o *observer) clone() *observer {
+	copied := *o
+	copied.pending = append([]transition(nil), o.pending...)
+	return &copied
+}
+
+// observe records an entity's move between archetypes with every observer
+func (cm *ComponentManager) observe(entity Entity, src, dst *archetype) {
+	for _, o := range cm.observers {
+		o.moved(entity, src, dst)
+	}
+}
+
+// Observe calls onEnter for every entity that starts matching q and onExit
+// for every entity that stops matching it, as components are added and
+// removed. Either may be nil. Entities that already match when the observer
+// is registered are not reported, nor are entities without components, and
+// Added and Changed terms of q are ignored.
+//
+// Observers are notified during Update: at its start, for changes made
+// since the previous update, after each stage and after events are
+// processed. Callbacks run one at a time and may change the world directly;
+// observers are notified again until the changes settle.
+func (w *World) Observe(q *Query, onEnter, onExit ObserverFunc) {
+	o := newObserver(q, onEnter, onExit)
+	w.observers = append(w.observers, o)
+	w.ComponentManager.observers = append(w.ComponentManager.observers, o)
+}
+
+// runObservers applies queued commands and notifies observers, repeating
+// while callbacks cause further changes
+func (w *World) runObservers() {
+	for range maxObserverRounds {
+		w.applyCommands()
+		notified := false
+		for _, o := range w.observers {
+			if o.notify(w) {
+				notified = true
+			}
+		}
+		if !notified {
+			return
+		}
+	}
+	w.Logger.Warn("observers still triggering, giving up", "rounds", maxObserverRounds)
+}
+
+// RunOnEnter only runs the system on updates where an entity started
+// matching q since the system last ran. A system with several triggers runs
+// when any of them fires.
+func RunOnEnter(q *Query) SystemOption {
+	return func(entry *systemEntry) {
+		entry.triggers = append(entry.triggers, trigger{
+			observer: newObserver(q, nil, nil),
+			onEnter:  true,
+		})
+	}
+}
+
+// RunOnExit only runs the system on updates where an entity stopped
+// matching q since the system last ran. See RunOnEnter.
+func RunOnExit(q *Query) SystemOption {
+	return func(entry *systemEntry) {
+		entry.triggers = append(entry.triggers, trigger{
+			observer: newObserver(q, nil, nil),
+			onExit:   true,
+		})
+	}
+}
+
+// trigger makes a system run only when its query's membership changes
+type trigger struct {
+	observer *observer
+	onEnter  bool
+	onExit   bool
+}
+
+// fired reports whether the trigger's condition occurred since the last check
+func (t trigger) fired() bool {
+	fired := false
+	for _, tr := range t.observer.take() {
+		if tr.entered && t.onEnter || !tr.entered && t.onExit {
+			fired = true
+		}
+	}
+	return fired
+}
@@ -0,0 +1,143 @@
+package ecs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+)
+
+// PrefabComponent is a component value in a prefab, or an override given
+// when instantiating one
+type PrefabComponent struct {
+	id  ComponentID
+	add func(w *World, entity Entity) error
+}
+
+// Component wraps a component value for NewPrefab and Instantiate. Every
+// instance gets its own copy, deep if T implements Cloner.
+func Component[T any](value T) PrefabComponent {
+	return PrefabComponent{
+		id: ID[T](),
+		add: func(w *World, entity Entity) error {
+			return Add(w, entity, cloneValue(&value))
+		},
+	}
+}
+
+// Prefab is a named entity template, a set of component values every
+// instance starts with
+type Prefab struct {
+	Name       string
+	components []PrefabComponent
+}
+
+// NewPrefab declares a prefab in Go. A later component replaces an earlier
+// one of the same type.
+func NewPrefab(name string, components ...PrefabComponent) *Prefab {
+	p := &Prefab{Name: name}
+	p.components = withOverrides(nil, components)
+	return p
+}
+
+// Instantiate creates an entity from the prefab. Overrides replace the
+// prefab's components of the same type and add the others.
+func (p *Prefab) Instantiate(w *World, overrides ...PrefabComponent) (Entity, error) {
+	entity := w.EntityManager.CreateEntity()
+	if err := p.addTo(w, entity, overrides); err != nil {
+		w.RemoveEntity(entity)
+		return NoEntity, err
+	}
+	return entity, nil
+}
+
+// Instantiate reserves an entity and queues its creation from the prefab,
+// see Prefab.Instantiate. If instantiating fails the entity is removed again.
+func (c *Commands) Instantiate(p *Prefab, overrides ...PrefabComponent) Entity {
+	entity := c.CreateEntity()
+	c.push(func(w *World) error {
+		if err := p.addTo(w, entity, overrides); err != nil {
+			w.RemoveEntity(entity)
+			return err
+		}
+		return nil
+	})
+	return entity
+}
+
+func (p *Prefab) addTo(w *World, entity Entity, overrides []PrefabComponent) error {
+	for _, pc := range withOverrides(p.components, overrides) {
+		if err := pc.add(w, entity); err != nil {
+			return fmt.Errorf("ecs: instantiating prefab %q: %w", p.Name, err)
+		}
+	}
+	return nil
+}
+
+// withOverrides returns components with overrides applied, keeping the
+// position of replaced components
+func withOverrides(components, overrides []PrefabComponent) []PrefabComponent {
+	if len(overrides) == 0 {
+		return components
+	}
+	result := slices.Clone(components)
+	for _, o := range overrides {
+		i := slices.IndexFunc(result, func(pc PrefabComponent) bool { return pc.id == o.id })
+		if i >= 0 {
+			result[i] = o
+		} else {
+			result = append(result, o)
+		}
+	}
+	return result
+}
+
+// LoadPrefabs reads prefabs from JSON. The document maps prefab names to
+// objects mapping registered component names to their values:
+//
+//	{"player_x": {"player": {"Character": "X"}, "human": {}}}
+//
+// Components are added in name order. Every value is checked while loading,
+// including for unknown fields, so mistakes surface before anything is
+// instantiated.
+func LoadPrefabs(in io.Reader) (map[string]*Prefab, error) {
+	var doc map[string]map[string]json.RawMessage
+	if err := json.NewDecoder(in).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("ecs: decoding prefabs: %w", err)
+	}
+
+	// Values are decoded into a scratch entity to validate them
+	scratch := NewWorld(nil)
+	prefabs := make(map[string]*Prefab, len(doc))
+	for name, values := range doc {
+		p := &Prefab{Name: name}
+		for _, componentName := range slices.Sorted(maps.Keys(values)) {
+			info, exists := registry.lookupName(componentName)
+			if !exists {
+				return nil, fmt.Errorf("ecs: prefab %q has unregistered component %q", name, componentName)
+			}
+			id, _ := registry.lookup(info.typ)
+			pc := jsonComponent(id, info, values[componentName])
+			if err := pc.add(scratch, scratch.EntityManager.CreateEntity()); err != nil {
+				return nil, fmt.Errorf("ecs: decoding component %q of prefab %q: %w", componentName, name, err)
+			}
+			p.components = append(p.components, pc)
+		}
+		prefabs[name] = p
+	}
+	return prefabs, nil
+}
+
+// jsonComponent decodes a fresh component value from raw for every instance
+func jsonComponent(id ComponentID, info componentInfo, raw json.RawMessage) PrefabComponent {
+	return PrefabComponent{
+		id: id,
+		add: func(w *World, entity Entity) error {
+			dec := json.NewDecoder(bytes.NewReader(raw))
+			dec.DisallowUnknownFields()
+			return info.decode(dec, w, entity)
+		},
+	}
+}
@@ -0,0 +1,60 @@
+package ecs
+
+import (
+	"strings"
+	"testing"
+)
+
+type prefabPos struct{ X, Y int }
+
+func init() {
+	RegisterComponent[prefabPos]("prefab_pos")
+}
+
+func TestPrefabFailureRemovesEntity(t *testing.T) {
+	w := NewWorld(nil)
+	prefab := NewPrefab("broken", Component(prefabPos{X: 1}), PrefabComponent{
+		id:  ID[struct{ broken bool }](),
+		add: func(w *World, e Entity) error { return ErrEntityNotAlive },
+	})
+
+	if _, err := prefab.Instantiate(w); err == nil {
+		t.Fatal("Prefab.Instantiate did not fail")
+	}
+	queued := w.Commands().Instantiate(prefab)
+	if err := w.Update(); err != nil {
+		t.Fatal(err)
+	}
+	if w.IsAlive(queued) {
+		t.Error("Commands.Instantiate left the entity of a failed instantiation alive")
+	}
+	if n := w.EntityManager.Count(); n != 0 {
+		t.Errorf("%d entities left after failed instantiations, want 0", n)
+	}
+}
+
+func TestPrefabOverrides(t *testing.T) {
+	prefabs, err := LoadPrefabs(strings.NewReader(`{"piece": {"prefab_pos": {"X": 1, "Y": 2}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := NewWorld(nil)
+	plain, err := prefabs["piece"].Instantiate(w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	moved, err := prefabs["piece"].Instantiate(w, Component(prefabPos{X: 5}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, _ := Get[prefabPos](w, plain); *p != (prefabPos{X: 1, Y: 2}) {
+		t.Errorf("plain instance has %+v", *p)
+	}
+	if p, _ := Get[prefabPos](w, moved); *p != (prefabPos{X: 5}) {
+		t.Errorf("overridden instance has %+v", *p)
+	}
+
+	if _, err := LoadPrefabs(strings.NewReader(`{"piece": {"prefab_pos": {"Z": 1}}}`)); err == nil {
+		t.Error("LoadPrefabs accepted an unknown field")
+	}
+}
@@ -0,0 +1,325 @@
+package ecs
+
+import "slices"
+
+// Filter describes which entities a query matches
+type Filter struct {
+	all  []ComponentID
+	any  []ComponentID
+	none []ComponentID
+
+	// Change terms, see Added and Changed
+	added   []ComponentID
+	changed []ComponentID
+
+	// Relation terms, see RelatedTo
+	related []relatedTerm
+}
+
+// QueryTerm adds a constraint to a query's filter
+type QueryTerm func(f *Filter)
+
+// All requires matching entities to have every listed component
+func All(ids ...ComponentID) QueryTerm {
+	return func(f *Filter) {
+		f.all = append(f.all, ids...)
+	}
+}
+
+// Any requires matching entities to have at least one of the listed components
+func Any(ids ...ComponentID) QueryTerm {
+	return func(f *Filter) {
+		f.any = append(f.any, ids...)
+	}
+}
+
+// None excludes entities that have any of the listed components
+func None(ids ...ComponentID) QueryTerm {
+	return func(f *Filter) {
+		f.none = append(f.none, ids...)
+	}
+}
+
+// With requires matching entities to have the component or tag T
+func With[T any]() QueryTerm {
+	return All(ID[T]())
+}
+
+// Without excludes entities that have the component or tag T
+func Without[T any]() QueryTerm {
+	return None(ID[T]())
+}
+
+func newFilter(terms []QueryTerm) Filter {
+	var f Filter
+	for _, term := range terms {
+		term(&f)
+	}
+	return f
+}
+
+// Matches reports whether entity satisfies the filter. Added and Changed
+// terms only require the components to be present.
+func (f *Filter) Matches(w *World, entity Entity) bool {
+	arch, row := w.ComponentManager.lookup(entity)
+	return f.matchesArchetype(arch) && (arch == nil || f.relatedAt(arch, row))
+}
+
+// matchesArchetype reports whether entities in arch satisfy the filter. A nil
+// archetype stands for entities without any components.
+func (f *Filter) matchesArchetype(arch *archetype) bool {
+	if arch == nil {
+		return len(f.all) == 0 && len(f.any) == 0
+	}
+	for _, id := range f.all {
+		if !arch.has(id) {
+			return false
+		}
+	}
+	for _, id := range f.none {
+		if arch.has(id) {
+			return false
+		}
+	}
+	if len(f.any) == 0 {
+		return true
+	}
+	for _, id := range f.any {
+		if arch.has(id) {
+			return true
+		}
+	}
+	return false
+}
+
+// Query is a reusable, cached entity query. The matching entities are
+// recomputed only after the world's components change structurally, so
+// systems should build their queries once and keep them. Results are
+// always in ascending entity ID order.
+//
+// A query with Added or Changed terms only returns the entities whose
+// components changed since its previous call to Entities or Each, so each
+// system needs its own.
+type Query struct {
+	filter   Filter
+	cm       *ComponentManager // storage the cached result belongs to
+	version  uint64
+	entities []Entity
+	since    Tick // when the change terms were last evaluated
+}
+
+// NewQuery creates a query from the given terms
+func NewQuery(terms ...QueryTerm) *Query {
+	return &Query{filter: newFilter(terms)}
+}
+
+// Entities returns the entities in world that match the query.
+// The returned slice must not be modified.
+func (q *Query) Entities(w *World) []Entity {
+	if q.cm != w.ComponentManager || q.version != w.structureVersion() || q.entities == nil {
+		q.refresh(w)
+	}
+	if !q.filter.tracksChanges() {
+		return q.entities
+	}
+	since := q.since
+	q.since = w.ChangeTick()
+	return q.filter.changedSince(w.ComponentManager, q.entities, since)
+}
+
+// Matches reports whether entity matches the query
+func (q *Query) Matches(w *World, entity Entity) bool {
+	return q.filter.Matches(w, entity)
+}
+
+func (q *Query) refresh(w *World) {
+	// Always build a fresh slice so callers iterating the previous result
+	// are unaffected by structural changes they make along the way
+	var entities []Entity
+	if len(q.filter.all) == 0 && len(q.filter.any) == 0 {
+		// Entities without components can match too, so start from every entity
+		for _, e := range w.EntityManager.GetAllEntities() {
+			if q.filter.Matches(w, e) {
+				entities = append(entities, e)
+			}
+		}
+	} else {
+		matched := make([]*archetype, 0)
+		count := 0
+		for _, arch := range w.ComponentManager.archetypes {
+			if q.filter.matchesArchetype(arch) {
+				matched = append(matched, arch)
+				count += len(arch.entities)
+			}
+		}
+		entities = make([]Entity, 0, count)
+		for _, arch := range matched {
+			if len(q.filter.related) == 0 {
+				entities = append(entities, arch.entities...)
+				continue
+			}
+			for row, e := range arch.entities {
+				if q.filter.relatedAt(arch, row) {
+					entities = append(entities, e)
+				}
+			}
+		}
+		slices.Sort(entities)
+	}
+	if entities == nil {
+		entities = []Entity{}
+	}
+	q.cm = w.ComponentManager
+	q.version = w.structureVersion()
+	q.entities = entities
+}
+
+// viewCache remembers, per archetype, the typed columns a query reads so
+// iteration needs neither map lookups nor type assertions
+type viewCache[V any] struct {
+	cm    *ComponentManager
+	views []V
+}
+
+func (c *viewCache[V]) get(
+	cm *ComponentManager,
+	arch *archetype,
+	filter *Filter,
+	build func(filter *Filter, arch *archetype) V,
+) V {
+	if c.cm != cm {
+		c.cm = cm
+		c.views = c.views[:0]
+	}
+	for len(c.views) <= arch.id {
+		c.views = append(c.views, build(filter, cm.archetypes[len(c.views)]))
+	}
+	return c.views[arch.id]
+}
+
+// Query1 is a query over entities with component A
+type Query1[A any] struct {
+	*Query
+	views viewCache[view1[A]]
+}
+
+type view1[A any] struct {
+	a *typedColumn[A]
+}
+
+func buildView1[A any](filter *Filter, arch *archetype) view1[A] {
+	if !filter.matchesArchetype(arch) {
+		return view1[A]{}
+	}
+	return view1[A]{a: typedColumnOf[A](arch, ID[A]())}
+}
+
+// NewQuery1 creates a query matching entities with A and the given terms
+func NewQuery1[A any](terms ...QueryTerm) *Query1[A] {
+	terms = append([]QueryTerm{All(ID[A]())}, terms...)
+	return &Query1[A]{Query: NewQuery(terms...)}
+}
+
+// Each calls fn for every matching entity along with its components
+func (q *Query1[A]) Each(w *World, fn func(entity Entity, a *A)) {
+	cm := w.ComponentManager
+	for _, e := range q.Entities(w) {
+		arch, row := cm.lookup(e)
+		if arch == nil {
+			continue
+		}
+		v := q.views.get(cm, arch, &q.filter, buildView1[A])
+		if v.a == nil {
+			continue // no longer matches after a change made during iteration
+		}
+		fn(e, &v.a.data[row])
+	}
+}
+
+// Query2 is a query over entities with components A and B
+type Query2[A, B any] struct {
+	*Query
+	views viewCache[view2[A, B]]
+}
+
+type view2[A, B any] struct {
+	a *typedColumn[A]
+	b *typedColumn[B]
+}
+
+func buildView2[A, B any](filter *Filter, arch *archetype) view2[A, B] {
+	if !filter.matchesArchetype(arch) {
+		return view2[A, B]{}
+	}
+	return view2[A, B]{
+		a: typedColumnOf[A](arch, ID[A]()),
+		b: typedColumnOf[B](arch, ID[B]()),
+	}
+}
+
+// NewQuery2 creates a query matching entities with A, B and the given terms
+func NewQuery2[A, B any](terms ...QueryTerm) *Query2[A, B] {
+	terms = append([]QueryTerm{All(ID[A](), ID[B]())}, terms...)
+	return &Query2[A, B]{Query: NewQuery(terms...)}
+}
+
+// Each calls fn for every matching entity along with its components
+func (q *Query2[A, B]) Each(w *World, fn func(entity Entity, a *A, b *B)) {
+	cm := w.ComponentManager
+	for _, e := range q.Entities(w) {
+		arch, row := cm.lookup(e)
+		if arch == nil {
+			continue
+		}
+		v := q.views.get(cm, arch, &q.filter, buildView2[A, B])
+		if v.a == nil {
+			continue // no longer matches after a change made during iteration
+		}
+		fn(e, &v.a.data[row], &v.b.data[row])
+	}
+}
+
+// Query3 is a query over entities with components A, B and C
+type Query3[A, B, C any] struct {
+	*Query
+	views viewCache[view3[A, B, C]]
+}
+
+type view3[A, B, C any] struct {
+	a *typedColumn[A]
+	b *typedColumn[B]
+	c *typedColumn[C]
+}
+
+func buildView3[A, B, C any](filter *Filter, arch *archetype) view3[A, B, C] {
+	if !filter.matchesArchetype(arch) {
+		return view3[A, B, C]{}
+	}
+	return view3[A, B, C]{
+		a: typedColumnOf[A](arch, ID[A]()),
+		b: typedColumnOf[B](arch, ID[B]()),
+		c: typedColumnOf[C](arch, ID[C]()),
+	}
+}
+
+// NewQuery3 creates a query matching entities with A, B, C and the given terms
+func NewQuery3[A, B, C any](terms ...QueryTerm) *Query3[A, B, C] {
+	terms = append([]QueryTerm{All(ID[A](), ID[B](), ID[C]())}, terms...)
+	return &Query3[A, B, C]{Query: NewQuery(terms...)}
+}
+
+// Each calls fn for every matching entity along with its components
+func (q *Query3[A, B, C]) Each(w *World, fn func(entity Entity, a *A, b *B, c *C)) {
+	cm := w.ComponentManager
+	for _, e := range q.Entities(w) {
+		arch, row := cm.lookup(e)
+		if arch == nil {
+			continue
+		}
+		v := q.views.get(cm, arch, &q.filter, buildView3[A, B, C])
+		if v.a == nil {
+			continue // no longer matches after a change made during iteration
+		}
+		fn(e, &v.a.data[row], &v.b.data[row], &v.c.data[row])
+	}
+}
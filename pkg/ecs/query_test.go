@@ -0,0 +1,109 @@
+package ecs
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+type orderA struct{ N int }
+type orderB struct{ N int }
+
+// orderRun is the result of one run of the ordering scenario
+type orderRun struct {
+	all, withA, queryA, queryAB, eachAB []Entity
+}
+
+// runOrderScenario creates, removes and recycles entities in an order
+// fixed by seed, adding and removing components along the way
+func runOrderScenario(seed int64) orderRun {
+	rng := rand.New(rand.NewSource(seed))
+	w := NewWorld(nil)
+	queryA := NewQuery(With[orderA]())
+	queryAB := NewQuery2[orderA, orderB]()
+	var live []Entity
+	for i := range 500 {
+		switch op := rng.Intn(10); {
+		case op < 5 || len(live) == 0:
+			e := w.EntityManager.CreateEntity()
+			// Alternate the order components are added in, so the same set
+			// is reached through different archetype paths
+			if i%2 == 0 {
+				Add(w, e, orderA{i})
+				Add(w, e, orderB{i})
+			} else {
+				Add(w, e, orderB{i})
+				Add(w, e, orderA{i})
+			}
+			live = append(live, e)
+		case op < 8:
+			j := rng.Intn(len(live))
+			w.RemoveEntity(live[j])
+			live = slices.Delete(live, j, j+1)
+		default:
+			e := live[rng.Intn(len(live))]
+			if rng.Intn(2) == 0 {
+				Remove[orderB](w, e)
+			} else {
+				Remove[orderA](w, e)
+			}
+		}
+		// Query between changes so cached results are refreshed repeatedly
+		if i%37 == 0 {
+			queryA.Entities(w)
+		}
+	}
+
+	var run orderRun
+	run.all = w.EntityManager.GetAllEntities()
+	run.withA = w.ComponentManager.GetAllEntitiesWithComponent(ID[orderA]())
+	run.queryA = slices.Clone(queryA.Entities(w))
+	run.queryAB = slices.Clone(queryAB.Entities(w))
+	queryAB.Each(w, func(e Entity, _ *orderA, _ *orderB) {
+		run.eachAB = append(run.eachAB, e)
+	})
+	return run
+}
+
+func TestEntityOrderIsAscendingAndRepeatable(t *testing.T) {
+	first := runOrderScenario(1)
+	results := map[string][]Entity{
+		"GetAllEntities":              first.all,
+		"GetAllEntitiesWithComponent": first.withA,
+		"Query.Entities":              first.queryA,
+		"Query2.Entities":             first.queryAB,
+		"Query2.Each":                 first.eachAB,
+	}
+	for name, entities := range results {
+		if len(entities) == 0 {
+			t.Fatalf("%s returned no entities, the scenario is too small", name)
+		}
+		if !slices.IsSorted(entities) {
+			t.Errorf("%s is not in ascending ID order: %v", name, entities)
+		}
+	}
+	if !slices.Equal(first.withA, first.queryA) {
+		t.Errorf("GetAllEntitiesWithComponent %v and Query.Entities %v differ", first.withA, first.queryA)
+	}
+	if !slices.Equal(first.queryAB, first.eachAB) {
+		t.Errorf("Query2.Entities %v and Query2.Each %v differ", first.queryAB, first.eachAB)
+	}
+	recycled := slices.ContainsFunc(first.all, func(e Entity) bool { return e.Generation() > 0 })
+	if !recycled {
+		t.Fatal("the scenario never recycled an entity index")
+	}
+
+	for range 10 {
+		again := runOrderScenario(1)
+		for name, pair := range map[string][2][]Entity{
+			"GetAllEntities":              {first.all, again.all},
+			"GetAllEntitiesWithComponent": {first.withA, again.withA},
+			"Query.Entities":              {first.queryA, again.queryA},
+			"Query2.Each":                 {first.eachAB, again.eachAB},
+		} {
+			if !slices.Equal(pair[0], pair[1]) {
+				t.Fatalf("%s differs between identical runs:\n%v\n%v", name, pair[0], pair[1])
+			}
+		}
+	}
+}
@@ -0,0 +1,294 @@
+package ecs
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Relations link a source entity to one or more target entities. A relation
+// kind is a Go type, usually an empty struct like ChildOf, and the targets
+// of an entity's R relations are kept in its Relation[R] component, so
+// relations work with queries, snapshots and clones like any component.
+// Relations to an entity are dropped when it is removed, and sources
+// related to it by a kind registered with Cascade are removed as well.
+
+// ChildOf places an entity under a parent. An entity has at most one parent,
+// and removing the parent removes its children.
+type ChildOf struct{}
+
+// OwnedBy marks an entity as belonging to another, such as a piece to the
+// player holding it. An entity has at most one owner.
+type OwnedBy struct{}
+
+func init() {
+	RegisterRelation[ChildOf]("child_of", Exclusive(), Cascade())
+	RegisterRelation[OwnedBy]("owned_by", Exclusive())
+}
+
+// Relation holds the targets of an entity's R relations, in the order they
+// were added. Change it through Relate and Unrelate so queries and removals
+// stay consistent.
+type Relation[R any] struct {
+	Targets []Entity
+}
+
+// Clone copies the targets, see Cloner
+func (r Relation[R]) Clone() Relation[R] {
+	return Relation[R]{Targets: slices.Clone(r.Targets)}
+}
+
+// relationValue gives access to the targets of any Relation[R]
+type relationValue interface {
+	relationTargets() []Entity
+	dropTarget(target Entity)
+}
+
+func (r *Relation[R]) relationTargets() []Entity {
+	return r.Targets
+}
+
+func (r *Relation[R]) dropTarget(target Entity) {
+	r.Targets = slices.DeleteFunc(r.Targets, func(e Entity) bool { return e == target })
+}
+
+// relationInfo describes how a relation kind behaves
+type relationInfo struct {
+	exclusive bool
+	cascade   bool
+}
+
+// RelationOption configures a relation kind, see RegisterRelation
+type RelationOption func(info *relationInfo)
+
+// Exclusive lets a source have at most one target of the kind; relating it
+// to another target replaces the previous one
+func Exclusive() RelationOption {
+	return func(info *relationInfo) {
+		info.exclusive = true
+	}
+}
+
+// Cascade removes the sources of a relation along with its target
+func Cascade() RelationOption {
+	return func(info *relationInfo) {
+		info.cascade = true
+	}
+}
+
+// RegisterRelation names the relation kind R for snapshots and sets how it
+// behaves. Kinds that are never registered can still be used, with any
+// number of targets and no cascading. Like RegisterComponent it is meant to
+// be called during initialization.
+func RegisterRelation[R any](name string, opts ...RelationOption) {
+	var info relationInfo
+	for _, opt := range opts {
+		opt(&info)
+	}
+	registry.relation(ID[Relation[R]](), info, true)
+	RegisterComponent[Relation[R]](name)
+}
+
+// relationID returns the component ID holding relations of kind R
+func relationID[R any]() ComponentID {
+	id := ID[Relation[R]]()
+	registry.relation(id, relationInfo{}, false)
+	return id
+}
+
+// Relate adds an R relation from source to target. Relations are
+// structural changes, so systems that run concurrently must use
+// AddRelation instead.
+func Relate[R any](w *World, source, target Entity) error {
+	cm := w.ComponentManager
+	checkNotFrozen(cm.frozen)
+	id := relationID[R]()
+	if !w.IsAlive(target) {
+		return fmt.Errorf("%w: %v", ErrEntityNotAlive, target)
+	}
+	rel, exists := Get[Relation[R]](w, source)
+	if !exists {
+		return Add(w, source, Relation[R]{Targets: []Entity{target}})
+	}
+	if slices.Contains(rel.Targets, target) {
+		return nil
+	}
+	if registry.info(id).relation.exclusive {
+		rel.Targets = rel.Targets[:0]
+	}
+	rel.Targets = append(rel.Targets, target)
+	cm.markChanged(source, id)
+	cm.version++
+	return nil
+}
+
+// Unrelate removes the R relation from source to target, if any
+func Unrelate[R any](w *World, source, target Entity) {
+	cm := w.ComponentManager
+	checkNotFrozen(cm.frozen)
+	cm.dropRelation(source, relationID[R](), target)
+}
+
+// dropRelation removes target from the source's relation component with
+// the given ID, removing the component once it has no targets left
+func (cm *ComponentManager) dropRelation(source Entity, id ComponentID, target Entity) {
+	value, exists := cm.GetComponent(source, id)
+	if !exists {
+		return
+	}
+	rel := value.(relationValue)
+	if !slices.Contains(rel.relationTargets(), target) {
+		return
+	}
+	rel.dropTarget(target)
+	if len(rel.relationTargets()) == 0 {
+		cm.RemoveComponent(source, id)
+		return
+	}
+	cm.markChanged(source, id)
+	cm.version++
+}
+
+// HasRelation reports whether source has an R relation to target
+func HasRelation[R any](w *World, source, target Entity) bool {
+	return slices.Contains(Targets[R](w, source), target)
+}
+
+// Targets returns the entities source has an R relation to. The returned
+// slice must not be modified.
+func Targets[R any](w *World, source Entity) []Entity {
+	rel, exists := Get[Relation[R]](w, source)
+	if !exists {
+		return nil
+	}
+	return rel.Targets
+}
+
+// Target returns the first entity source has an R relation to
+func Target[R any](w *World, source Entity) (Entity, bool) {
+	targets := Targets[R](w, source)
+	if len(targets) == 0 {
+		return NoEntity, false
+	}
+	return targets[0], true
+}
+
+// Sources returns the entities with an R relation to target, in ascending
+// ID order
+func Sources[R any](w *World, target Entity) []Entity {
+	return w.ComponentManager.sources(relationID[R](), target)
+}
+
+// Parent returns the entity's parent, see ChildOf
+func Parent(w *World, entity Entity) (Entity, bool) {
+	return Target[ChildOf](w, entity)
+}
+
+// Children returns the entities whose parent is entity, in ascending ID order
+func Children(w *World, entity Entity) []Entity {
+	return Sources[ChildOf](w, entity)
+}
+
+// sources returns the entities whose relation component with the given ID
+// includes target
+func (cm *ComponentManager) sources(id ComponentID, target Entity) []Entity {
+	entities := []Entity{}
+	for _, arch := range cm.archetypes {
+		col := arch.column(id)
+		if col < 0 {
+			continue
+		}
+		for row, source := range arch.entities {
+			rel := arch.columns[col].get(row).(relationValue)
+			if slices.Contains(rel.relationTargets(), target) {
+				entities = append(entities, source)
+			}
+		}
+	}
+	slices.Sort(entities)
+	return entities
+}
+
+// removeRelationsTo drops every relation to a removed entity, removing the
+// sources of cascading relations
+func (w *World) removeRelationsTo(target Entity) {
+	cm := w.ComponentManager
+	for _, id := range registry.relationIDs() {
+		cascade := registry.info(id).relation.cascade
+		for _, source := range cm.sources(id, target) {
+			if cascade {
+				w.RemoveEntity(source)
+			} else {
+				cm.dropRelation(source, id, target)
+			}
+		}
+	}
+}
+
+// relatedTerm requires an entity's relation component to include target
+type relatedTerm struct {
+	id     ComponentID
+	target Entity
+}
+
+// RelatedTo requires matching entities to have an R relation to target, so
+// NewQuery(RelatedTo[ChildOf](board)) matches the board's children. Like
+// Added and Changed terms, it is ignored by observers.
+func RelatedTo[R any](target Entity) QueryTerm {
+	id := relationID[R]()
+	return func(f *Filter) {
+		f.all = append(f.all, id)
+		f.related = append(f.related, relatedTerm{id: id, target: target})
+	}
+}
+
+// relatedAt reports whether the entity at row of arch satisfies the
+// filter's RelatedTo terms
+func (f *Filter) relatedAt(arch *archetype, row int) bool {
+	for _, term := range f.related {
+		col := arch.column(term.id)
+		if col < 0 {
+			return false
+		}
+		rel := arch.columns[col].get(row).(relationValue)
+		if !slices.Contains(rel.relationTargets(), term.target) {
+			return false
+		}
+	}
+	return true
+}
+
+// AddRelation queues adding an R relation from source to target
+func AddRelation[R any](c *Commands, source, target Entity) {
+	c.push(func(w *World) error {
+		return Relate[R](w, source, target)
+	})
+}
+
+// RemoveRelation queues removing the R relation from source to target
+func RemoveRelation[R any](c *Commands, source, target Entity) {
+	c.push(func(w *World) error {
+		Unrelate[R](w, source, target)
+		return nil
+	})
+}
+
+// checkRelations reports relations to entities that are not alive
+func (cm *ComponentManager) checkRelations() error {
+	for _, id := range registry.relationIDs() {
+		for _, arch := range cm.archetypes {
+			col := arch.column(id)
+			if col < 0 {
+				continue
+			}
+			for row, source := range arch.entities {
+				for _, target := range arch.columns[col].get(row).(relationValue).relationTargets() {
+					if !cm.entities.IsAlive(target) {
+						return fmt.Errorf("ecs: entity %s has a %v relation to %v, which is not alive",
+							cm.entities.label(source), id, target)
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
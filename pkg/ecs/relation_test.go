@@ -0,0 +1,117 @@
+package ecs
+
+import (
+	"slices"
+	"testing"
+)
+
+// relate relates source to target, failing the test on error
+func relate[R any](t *testing.T, w *World, source, target Entity) {
+	t.Helper()
+	if err := Relate[R](w, source, target); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRemovingParentRemovesDescendants(t *testing.T) {
+	w := NewWorld(nil)
+	parent := w.EntityManager.CreateEntity()
+	child := w.EntityManager.CreateEntity()
+	grandchild := w.EntityManager.CreateEntity()
+	other := w.EntityManager.CreateEntity()
+	relate[ChildOf](t, w, child, parent)
+	relate[ChildOf](t, w, grandchild, child)
+	relate[follows](t, w, other, grandchild)
+
+	w.RemoveEntity(parent)
+	for _, e := range []Entity{parent, child, grandchild} {
+		if w.IsAlive(e) {
+			t.Errorf("%v is alive after removing %v", e, parent)
+		}
+	}
+	if !w.IsAlive(other) {
+		t.Fatal("an entity following a descendant was removed, follows does not cascade")
+	}
+	if Has[Relation[follows]](w, other) {
+		t.Error("relation to a removed descendant was kept")
+	}
+}
+
+func TestChildOfIsExclusive(t *testing.T) {
+	w := NewWorld(nil)
+	first := w.EntityManager.CreateEntity()
+	second := w.EntityManager.CreateEntity()
+	child := w.EntityManager.CreateEntity()
+	relate[ChildOf](t, w, child, first)
+	relate[ChildOf](t, w, child, second)
+
+	if got := Targets[ChildOf](w, child); !slices.Equal(got, []Entity{second}) {
+		t.Errorf("parents %v, want only %v", got, second)
+	}
+	if got := Children(w, first); len(got) != 0 {
+		t.Errorf("previous parent still has children %v", got)
+	}
+	w.RemoveEntity(first)
+	if !w.IsAlive(child) {
+		t.Error("removing the previous parent removed the child")
+	}
+}
+
+func TestRemovingOwnerDropsOnlyTheRelation(t *testing.T) {
+	w := NewWorld(nil)
+	owner := w.EntityManager.CreateEntity()
+	piece := w.EntityManager.CreateEntity()
+	if err := Add(w, piece, orderA{1}); err != nil {
+		t.Fatal(err)
+	}
+	relate[OwnedBy](t, w, piece, owner)
+
+	w.RemoveEntity(owner)
+	if !w.IsAlive(piece) || !Has[orderA](w, piece) {
+		t.Fatal("removing the owner removed the piece or its components")
+	}
+	if Has[Relation[OwnedBy]](w, piece) {
+		t.Error("relation to the removed owner was kept")
+	}
+}
+
+func TestRemovingTargetKeepsOtherTargets(t *testing.T) {
+	w := NewWorld(nil)
+	source := w.EntityManager.CreateEntity()
+	a := w.EntityManager.CreateEntity()
+	b := w.EntityManager.CreateEntity()
+	relate[follows](t, w, source, a)
+	relate[follows](t, w, source, b)
+
+	w.RemoveEntity(a)
+	if got := Targets[follows](w, source); !slices.Equal(got, []Entity{b}) {
+		t.Errorf("targets %v after removing %v, want [%v]", got, a, b)
+	}
+}
+
+func TestRelatedToFollowsRelationChanges(t *testing.T) {
+	w := NewWorld(nil)
+	board := w.EntityManager.CreateEntity()
+	other := w.EntityManager.CreateEntity()
+	a := w.EntityManager.CreateEntity()
+	b := w.EntityManager.CreateEntity()
+	query := NewQuery(RelatedTo[ChildOf](board))
+	check := func(step string, want ...Entity) {
+		t.Helper()
+		if got := query.Entities(w); !slices.Equal(got, want) {
+			t.Errorf("%s: query returned %v, want %v", step, got, want)
+		}
+	}
+
+	check("no children")
+	relate[ChildOf](t, w, a, board)
+	check("a related", a)
+	relate[ChildOf](t, w, b, other)
+	check("b related elsewhere", a)
+	relate[ChildOf](t, w, b, board)
+	check("b moved to the board", a, b)
+	Unrelate[ChildOf](w, a, board)
+	check("a unrelated", b)
+	relate[ChildOf](t, w, b, other)
+	check("b moved away")
+}
@@ -0,0 +1,108 @@
+package ecs
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrResourceNotFound is returned when reading a resource that was never set
+var ErrResourceNotFound = errors.New("ecs: resource not found")
+
+// resource holds one world-wide singleton value
+type resource interface {
+	// get returns a pointer to the value, boxed as any
+	get() any
+	clone() resource
+	changeTicks() *componentTicks
+}
+
+type typedResource[T any] struct {
+	value T
+	ticks componentTicks
+}
+
+func (r *typedResource[T]) get() any {
+	return &r.value
+}
+
+func (r *typedResource[T]) clone() resource {
+	return &typedResource[T]{value: cloneValue(&r.value), ticks: r.ticks}
+}
+
+func (r *typedResource[T]) changeTicks() *componentTicks {
+	return &r.ticks
+}
+
+// SetResource stores value as the world's singleton of type T, replacing any
+// previous value, which counts as a change. Resources share IDs and registered names with components,
+// so ID[T] can be used in Reads/Writes declarations and RegisterComponent
+// makes T part of snapshots.
+func SetResource[T any](w *World, value T) {
+	id := ID[T]()
+	now := w.ComponentManager.now()
+	if r, exists := w.resources[id]; exists {
+		r.(*typedResource[T]).value = value
+		r.changeTicks().changed = now
+		return
+	}
+	checkNotFrozen(w.ComponentManager.frozen)
+	w.resources[id] = &typedResource[T]{
+		value: value,
+		ticks: componentTicks{added: now, changed: now},
+	}
+}
+
+// Resource returns a pointer to the world's singleton of type T, or an error
+// wrapping ErrResourceNotFound if it has not been set
+func Resource[T any](w *World) (*T, error) {
+	r, exists := w.resources[ID[T]()]
+	if !exists {
+		return nil, fmt.Errorf("%w: %v", ErrResourceNotFound, ID[T]())
+	}
+	return &r.(*typedResource[T]).value, nil
+}
+
+// ResourceMut is like Resource but also marks the resource as changed
+func ResourceMut[T any](w *World) (*T, error) {
+	value, err := Resource[T](w)
+	if err == nil {
+		MarkResourceChanged[T](w)
+	}
+	return value, err
+}
+
+// MarkResourceChanged flags the singleton of type T, if any, as changed
+func MarkResourceChanged[T any](w *World) {
+	if r, exists := w.resources[ID[T]()]; exists {
+		r.changeTicks().changed = w.ComponentManager.now()
+	}
+}
+
+// ResourceChanged reports whether the singleton of type T was set or
+// changed after since. Writes through the pointer returned by Resource are
+// not tracked; use ResourceMut to modify resources others react to.
+func ResourceChanged[T any](w *World, since Tick) bool {
+	r, exists := w.resources[ID[T]()]
+	return exists && r.changeTicks().changed > since
+}
+
+// HasResource reports whether the world has a singleton of type T
+func HasResource[T any](w *World) bool {
+	_, exists := w.resources[ID[T]()]
+	return exists
+}
+
+// RemoveResource deletes the world's singleton of type T, if any
+func RemoveResource[T any](w *World) {
+	checkNotFrozen(w.ComponentManager.frozen)
+	delete(w.resources, ID[T]())
+}
+
+func decodeResource[T any](dec Decoder, w *World) error {
+	var value T
+	if err := dec.Decode(&value); err != nil {
+		return err
+	}
+	SetResource(w, value)
+	return nil
+}
@@ -0,0 +1,200 @@
+package ecs
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Time is the resource a Runner sets before every update, for systems that
+// advance timers, animations and the like
+type Time struct {
+	// Delta is the simulated time the update covers, always the runner's step
+	Delta time.Duration
+	// Elapsed is the simulated time covered by all updates so far, this one
+	// included
+	Elapsed time.Duration
+}
+
+func init() {
+	RegisterComponent[Time]("time")
+}
+
+// CatchUpPolicy decides what a Runner does when updates fall behind the
+// clock, because an update or the machine was slow
+type CatchUpPolicy int
+
+const (
+	// CatchUp runs the missed updates back to back, up to the runner's
+	// MaxCatchUp, so simulated time keeps pace with the clock
+	CatchUp CatchUpPolicy = iota
+	// SkipMissed drops the missed updates and runs a single one, so
+	// simulated time falls behind the clock instead
+	SkipMissed
+)
+
+// DefaultStep is the step of runners created without TickRate, 60 updates
+// per second
+const DefaultStep = time.Second / 60
+
+// Runner drives a world's updates, either at a fixed rate with Run or one at
+// a time with Step, as when the game waits for player input. Either way every
+// update advances the Time resource by the same step, so the simulation does
+// not depend on how fast it runs.
+type Runner struct {
+	world      *World
+	step       time.Duration
+	policy     CatchUpPolicy
+	maxCatchUp int
+	paused     atomic.Bool
+	behind     time.Duration // clock time Run has not simulated yet
+
+	inboxMu sync.Mutex
+	inbox   []EventInterface // events sent from other goroutines, see Send
+}
+
+// RunnerOption configures a Runner
+type RunnerOption func(r *Runner)
+
+// TickRate sets how many updates a second Run aims for. It panics unless
+// hz is between 1 and 1e9.
+func TickRate(hz int) RunnerOption {
+	if hz < 1 || hz > int(time.Second) {
+		panic(fmt.Sprintf("ecs: tick rate %d is not between 1 and 1e9 updates a second", hz))
+	}
+	return func(r *Runner) {
+		r.step = time.Second / time.Duration(hz)
+	}
+}
+
+// FallBehind sets what Run does when updates fall behind, CatchUp by default
+func FallBehind(policy CatchUpPolicy) RunnerOption {
+	if policy != CatchUp && policy != SkipMissed {
+		panic(fmt.Sprintf("ecs: unknown catch-up policy %d", policy))
+	}
+	return func(r *Runner) {
+		r.policy = policy
+	}
+}
+
+// MaxCatchUp bounds how many updates Run runs back to back to catch up,
+// 5 by default. Updates missed beyond that are dropped. It panics if n is
+// less than 1.
+func MaxCatchUp(n int) RunnerOption {
+	if n < 1 {
+		panic(fmt.Sprintf("ecs: cannot catch up with %d updates at a time", n))
+	}
+	return func(r *Runner) {
+		r.maxCatchUp = n
+	}
+}
+
+// NewRunner creates a runner for w
+func NewRunner(w *World, opts ...RunnerOption) *Runner {
+	r := &Runner{world: w, step: DefaultStep, maxCatchUp: 5}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Send queues event for the next update. Unlike the world's methods it is
+// safe to call from any goroutine, such as those reading input or the
+// network while Run runs. The event is queued with World.QueueEvent before
+// the update, so event logs record it as an input.
+func (r *Runner) Send(event EventInterface) {
+	r.inboxMu.Lock()
+	defer r.inboxMu.Unlock()
+	r.inbox = append(r.inbox, event)
+}
+
+// Step queues the events passed to Send, advances the Time resource by one
+// step and updates the world once. It runs even while the runner is paused,
+// to advance a paused simulation frame by frame.
+func (r *Runner) Step() error {
+	r.inboxMu.Lock()
+	inbox := r.inbox
+	r.inbox = nil
+	r.inboxMu.Unlock()
+	for _, event := range inbox {
+		r.world.QueueEvent(event)
+	}
+
+	t, err := ResourceMut[Time](r.world)
+	if err != nil {
+		SetResource(r.world, Time{})
+		t, _ = ResourceMut[Time](r.world)
+	}
+	t.Delta = r.step
+	t.Elapsed += r.step
+	return r.world.Update()
+}
+
+// Run updates the world at the runner's tick rate until ctx is cancelled,
+// returning ctx's error, or until an update fails, returning that error.
+// Which update errors are returned depends on the world's error policy.
+//
+// The world belongs to Run's goroutine while it runs. Other goroutines must
+// not use it, and feed it events through Send instead.
+func (r *Runner) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.step)
+	defer ticker.Stop()
+
+	last := time.Now()
+	r.behind = 0
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case now := <-ticker.C:
+			elapsed := now.Sub(last)
+			last = now
+			if r.Paused() {
+				continue
+			}
+			for range r.due(elapsed) {
+				if err := r.Step(); err != nil {
+					return err
+				}
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+			}
+		}
+	}
+}
+
+// due accounts for clock time elapsed since the previous tick and returns
+// how many updates to run for it
+func (r *Runner) due(elapsed time.Duration) int {
+	r.behind += elapsed
+	steps := int(r.behind / r.step)
+	r.behind -= time.Duration(steps) * r.step
+	if r.policy == SkipMissed {
+		return min(steps, 1)
+	}
+	if steps > r.maxCatchUp {
+		r.world.Logger.Warn("runner falling behind, skipping updates",
+			"skipped", steps-r.maxCatchUp)
+		return r.maxCatchUp
+	}
+	return steps
+}
+
+// Pause stops Run from updating the world until Resume is called. Time
+// spent paused is not simulated. It is safe to call from any goroutine.
+func (r *Runner) Pause() {
+	r.paused.Store(true)
+}
+
+// Resume lets a paused runner update the world again
+func (r *Runner) Resume() {
+	r.paused.Store(false)
+}
+
+// Paused reports whether the runner is paused
+func (r *Runner) Paused() bool {
+	return r.paused.Load()
+}
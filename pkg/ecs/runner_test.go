@@ -0,0 +1,137 @@
+package ecs
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type runnerEvent struct{ N int }
+
+func (runnerEvent) Type() EventType { return "runner" }
+func (runnerEvent) Entity() Entity  { return NoEntity }
+func (e runnerEvent) Data() any     { return e }
+
+// countingSystem counts its updates, readable from other goroutines
+type countingSystem struct{ updates atomic.Int64 }
+
+func (s *countingSystem) Update(*World) error {
+	s.updates.Add(1)
+	return nil
+}
+
+func TestRunnerDue(t *testing.T) {
+	ms := time.Millisecond
+	tests := []struct {
+		name    string
+		opts    []RunnerOption
+		elapsed []time.Duration
+		want    []int
+	}{
+		{"on time", nil, []time.Duration{10 * ms, 10 * ms}, []int{1, 1}},
+		{"keeps the remainder", nil, []time.Duration{15 * ms, 5 * ms, 9 * ms}, []int{1, 1, 0}},
+		{"catches up", nil, []time.Duration{30 * ms, 10 * ms}, []int{3, 1}},
+		{"catches up at most MaxCatchUp", []RunnerOption{MaxCatchUp(2)},
+			[]time.Duration{50 * ms, 10 * ms}, []int{2, 1}},
+		{"skips missed", []RunnerOption{FallBehind(SkipMissed)},
+			[]time.Duration{50 * ms, 10 * ms}, []int{1, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRunner(NewWorld(nil), append([]RunnerOption{TickRate(100)}, tt.opts...)...)
+			for i, elapsed := range tt.elapsed {
+				if got := r.due(elapsed); got != tt.want[i] {
+					t.Fatalf("tick %d after %v: %d updates due, want %d", i, elapsed, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRunnerOptionsPanicOnInvalidValues(t *testing.T) {
+	for name, opt := range map[string]func(){
+		"zero tick rate":      func() { TickRate(0) },
+		"tick rate over 1e9":  func() { TickRate(int(time.Second) + 1) },
+		"zero catch up":       func() { MaxCatchUp(0) },
+		"unknown fall behind": func() { FallBehind(CatchUpPolicy(7)) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("option did not panic")
+				}
+			}()
+			opt()
+		})
+	}
+}
+
+func TestRunnerStep(t *testing.T) {
+	w := NewWorld(nil)
+	var received []int
+	Subscribe(w, func(e runnerEvent) { received = append(received, e.N) })
+	r := NewRunner(w, TickRate(50))
+	r.Pause()
+	r.Send(runnerEvent{N: 1})
+	for range 2 {
+		if err := r.Step(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tm, err := Resource[Time](w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tm.Delta != 20*time.Millisecond || tm.Elapsed != 40*time.Millisecond {
+		t.Errorf("Time after two steps is %+v", *tm)
+	}
+	if len(received) != 1 || received[0] != 1 {
+		t.Errorf("received %v, want the sent event once", received)
+	}
+}
+
+func TestRunnerRun(t *testing.T) {
+	w := NewWorld(nil)
+	counter := &countingSystem{}
+	w.AddSystem(counter)
+	var received atomic.Int64
+	Subscribe(w, func(runnerEvent) { received.Add(1) })
+	r := NewRunner(w, TickRate(200))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	r.Pause()
+	go func() { done <- r.Run(ctx) }()
+
+	time.Sleep(50 * time.Millisecond)
+	if n := counter.updates.Load(); n != 0 {
+		t.Errorf("%d updates while paused", n)
+	}
+
+	// Events can be sent from other goroutines while Run runs
+	r.Send(runnerEvent{N: 1})
+	r.Resume()
+	deadline := time.Now().Add(5 * time.Second)
+	for (counter.updates.Load() < 5 || received.Load() == 0) && time.Now().Before(deadline) {
+		r.Send(runnerEvent{N: 2})
+		time.Sleep(time.Millisecond)
+	}
+	if n := counter.updates.Load(); n < 5 {
+		t.Errorf("%d updates after resuming, want at least 5", n)
+	}
+	if received.Load() == 0 {
+		t.Error("events sent while running were not handled")
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Run returned %v, want %v", err, context.Canceled)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after cancelling")
+	}
+}
@@ -0,0 +1,404 @@
+package ecs
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Stage groups systems that run together. Stages run in order, and every
+// system in a stage finishes before the next stage starts.
+type Stage int
+
+const (
+	PreUpdate Stage = iota
+	Update
+	PostUpdate
+)
+
+var stages = []Stage{PreUpdate, Update, PostUpdate}
+
+func (s Stage) String() string {
+	switch s {
+	case PreUpdate:
+		return "PreUpdate"
+	case Update:
+		return "Update"
+	case PostUpdate:
+		return "PostUpdate"
+	}
+	return fmt.Sprintf("Stage(%d)", int(s))
+}
+
+// RunCondition decides whether a system runs on a given update
+type RunCondition func(w *World) bool
+
+// SystemOption configures how a system is scheduled
+type SystemOption func(entry *systemEntry)
+
+// Name sets the name other systems use to refer to this one. Systems
+// default to their Go type name.
+func Name(name string) SystemOption {
+	return func(entry *systemEntry) {
+		entry.name = name
+	}
+}
+
+// InStage places the system in a stage. Systems default to the Update stage.
+func InStage(stage Stage) SystemOption {
+	return func(entry *systemEntry) {
+		entry.stage = stage
+	}
+}
+
+// Before makes the system run before the named systems
+func Before(names ...string) SystemOption {
+	return func(entry *systemEntry) {
+		entry.before = append(entry.before, names...)
+	}
+}
+
+// After makes the system run after the named systems
+func After(names ...string) SystemOption {
+	return func(entry *systemEntry) {
+		entry.after = append(entry.after, names...)
+	}
+}
+
+// RunIf only runs the system on updates where cond returns true. Multiple
+// conditions must all hold.
+func RunIf(cond RunCondition) SystemOption {
+	return func(entry *systemEntry) {
+		entry.conditions = append(entry.conditions, cond)
+	}
+}
+
+// Reads declares the components the system reads. Systems that declare
+// their access with Reads and/or Writes may run concurrently with other
+// declared systems in the same stage when their accesses do not conflict.
+// Such systems must make structural changes through World.Commands.
+func Reads(ids ...ComponentID) SystemOption {
+	return func(entry *systemEntry) {
+		entry.declared = true
+		entry.reads = append(entry.reads, ids...)
+	}
+}
+
+// Writes declares the components the system modifies. See Reads.
+func Writes(ids ...ComponentID) SystemOption {
+	return func(entry *systemEntry) {
+		entry.declared = true
+		entry.writes = append(entry.writes, ids...)
+	}
+}
+
+// systemEntry is a system together with its scheduling configuration
+type systemEntry struct {
+	system     System
+	name       string
+	stage      Stage
+	before     []string
+	after      []string
+	conditions []RunCondition
+	triggers   []trigger
+	disabled   bool
+	timing     Timing // recorded when metrics are enabled
+
+	// Declared component access, only used when declared is set.
+	// Undeclared systems always run on their own.
+	declared bool
+	reads    []ComponentID
+	writes   []ComponentID
+}
+
+// conflicts reports whether the two systems could race if run concurrently
+func (e *systemEntry) conflicts(other *systemEntry) bool {
+	if !e.declared || !other.declared {
+		return true
+	}
+	for _, id := range e.writes {
+		if slices.Contains(other.writes, id) || slices.Contains(other.reads, id) {
+			return true
+		}
+	}
+	for _, id := range other.writes {
+		if slices.Contains(e.reads, id) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *systemEntry) shouldRun(w *World) bool {
+	if !e.enabled(w) {
+		// Drop the changes seen while skipped, so they do not fire the
+		// system once it runs again
+		for _, t := range e.triggers {
+			t.observer.take()
+		}
+		return false
+	}
+	if len(e.triggers) == 0 {
+		return true
+	}
+	// Check every trigger so none reports the same change twice
+	fired := false
+	for _, t := range e.triggers {
+		if t.fired() {
+			fired = true
+		}
+	}
+	return fired
+}
+
+// enabled reports whether the system is enabled and its run conditions hold
+func (e *systemEntry) enabled(w *World) bool {
+	if e.disabled {
+		return false
+	}
+	for _, cond := range e.conditions {
+		if !cond(w) {
+			return false
+		}
+	}
+	return true
+}
+
+// run updates the system, tagging any error with the system's name
+func (e *systemEntry) run(w *World) error {
+	var err error
+	w.instrument(e, func() {
+		err = e.system.Update(w)
+	})
+	if err != nil {
+		return &SystemError{System: e.name, Err: err}
+	}
+	return nil
+}
+
+// ErrSystemNotFound is returned when no system has the given name
+var ErrSystemNotFound = errors.New("ecs: system not found")
+
+// ErrScheduleCycle is returned when ordering constraints between systems form a cycle
+var ErrScheduleCycle = errors.New("ecs: system ordering cycle")
+
+// batch is a set of systems that can run at the same time
+type batch []*systemEntry
+
+// scheduler orders systems by stage and their before/after constraints, and
+// splits each stage into batches of systems that can run concurrently
+type scheduler struct {
+	entries []*systemEntry // in registration order
+	stages  [][]batch
+	dirty   bool
+}
+
+func (s *scheduler) add(system System, opts ...SystemOption) *systemEntry {
+	entry := &systemEntry{
+		system: system,
+		name:   fmt.Sprintf("%T", system),
+		stage:  Update,
+	}
+	for _, opt := range opts {
+		opt(entry)
+	}
+	s.entries = append(s.entries, entry)
+	s.dirty = true
+	return entry
+}
+
+// find returns the entry of the named system, or an error wrapping
+// ErrSystemNotFound
+func (s *scheduler) find(name string) (*systemEntry, error) {
+	for _, entry := range s.entries {
+		if entry.name == name {
+			return entry, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %q", ErrSystemNotFound, name)
+}
+
+// remove deletes the named system, unless others are ordered against it
+func (s *scheduler) remove(name string) (*systemEntry, error) {
+	entry, err := s.find(name)
+	if err != nil {
+		return nil, err
+	}
+	for _, other := range s.entries {
+		if other != entry && (slices.Contains(other.before, name) || slices.Contains(other.after, name)) {
+			return nil, fmt.Errorf("ecs: cannot remove system %q, %q is ordered against it", name, other.name)
+		}
+	}
+	s.entries = slices.DeleteFunc(s.entries, func(e *systemEntry) bool { return e == entry })
+	s.dirty = true
+	return entry, nil
+}
+
+// build validates the constraints and computes the run order of every stage
+func (s *scheduler) build() error {
+	byName := make(map[string]*systemEntry, len(s.entries))
+	for _, entry := range s.entries {
+		if _, exists := byName[entry.name]; exists {
+			return fmt.Errorf("ecs: duplicate system name %q", entry.name)
+		}
+		if slices.Index(stages, entry.stage) < 0 {
+			return fmt.Errorf("ecs: system %q has unknown stage %v", entry.name, entry.stage)
+		}
+		byName[entry.name] = entry
+	}
+
+	// edges[a] lists the systems that must run after a
+	edges := make(map[*systemEntry][]*systemEntry)
+	addEdge := func(first, then *systemEntry) error {
+		if first.stage != then.stage {
+			return fmt.Errorf(
+				"ecs: system %q (%v) cannot be ordered against %q (%v) in another stage",
+				first.name, first.stage, then.name, then.stage,
+			)
+		}
+		edges[first] = append(edges[first], then)
+		return nil
+	}
+	for _, entry := range s.entries {
+		for _, name := range entry.before {
+			other, exists := byName[name]
+			if !exists {
+				return fmt.Errorf("ecs: system %q runs before unknown system %q", entry.name, name)
+			}
+			if err := addEdge(entry, other); err != nil {
+				return err
+			}
+		}
+		for _, name := range entry.after {
+			other, exists := byName[name]
+			if !exists {
+				return fmt.Errorf("ecs: system %q runs after unknown system %q", entry.name, name)
+			}
+			if err := addEdge(other, entry); err != nil {
+				return err
+			}
+		}
+	}
+
+	ordered := make([][]batch, len(stages))
+	for i, stage := range stages {
+		var members []*systemEntry
+		for _, entry := range s.entries {
+			if entry.stage == stage {
+				members = append(members, entry)
+			}
+		}
+		order, err := topoSort(members, edges)
+		if err != nil {
+			return err
+		}
+		ordered[i] = batchSystems(order, edges)
+	}
+
+	s.stages = ordered
+	s.dirty = false
+	return nil
+}
+
+// topoSort orders members so every edge points forward. Systems without a
+// constraint between them keep their registration order.
+func topoSort(members []*systemEntry, edges map[*systemEntry][]*systemEntry) ([]*systemEntry, error) {
+	indegree := make(map[*systemEntry]int, len(members))
+	for _, entry := range members {
+		for _, next := range edges[entry] {
+			indegree[next]++
+		}
+	}
+
+	order := make([]*systemEntry, 0, len(members))
+	done := make(map[*systemEntry]bool, len(members))
+	for len(order) < len(members) {
+		progressed := false
+		for _, entry := range members {
+			if done[entry] || indegree[entry] > 0 {
+				continue
+			}
+			done[entry] = true
+			order = append(order, entry)
+			for _, next := range edges[entry] {
+				indegree[next]--
+			}
+			progressed = true
+			break
+		}
+		if !progressed {
+			return nil, fmt.Errorf("%w: %s", ErrScheduleCycle, describeCycle(members, edges, done))
+		}
+	}
+	return order, nil
+}
+
+// batchSystems greedily groups consecutive systems of a topologically sorted
+// stage into batches. A system joins the current batch only if it conflicts
+// with none of its members and none of them must run before it.
+func batchSystems(order []*systemEntry, edges map[*systemEntry][]*systemEntry) []batch {
+	var batches []batch
+	var current batch
+	for _, entry := range order {
+		joinable := len(current) > 0
+		for _, member := range current {
+			if member.conflicts(entry) || slices.Contains(edges[member], entry) {
+				joinable = false
+				break
+			}
+		}
+		if !joinable && len(current) > 0 {
+			batches = append(batches, current)
+			current = nil
+		}
+		current = append(current, entry)
+	}
+	if len(current) > 0 {
+		batches = append(batches, current)
+	}
+	return batches
+}
+
+// describeCycle finds a cycle among the unscheduled systems and formats it
+// as "a -> b -> a"
+func describeCycle(
+	members []*systemEntry,
+	edges map[*systemEntry][]*systemEntry,
+	done map[*systemEntry]bool,
+) string {
+	// Every remaining system has an unscheduled predecessor, so walking
+	// predecessors must eventually revisit a system
+	predecessor := make(map[*systemEntry]*systemEntry)
+	for _, entry := range members {
+		if done[entry] {
+			continue
+		}
+		for _, next := range edges[entry] {
+			if !done[next] {
+				predecessor[next] = entry
+			}
+		}
+	}
+
+	var start *systemEntry
+	for _, entry := range members {
+		if !done[entry] {
+			start = entry
+			break
+		}
+	}
+	seen := make(map[*systemEntry]bool)
+	for !seen[start] {
+		seen[start] = true
+		start = predecessor[start]
+	}
+
+	names := []string{start.name}
+	for entry := predecessor[start]; entry != start; entry = predecessor[entry] {
+		names = append(names, entry.name)
+	}
+	names = append(names, start.name)
+	slices.Reverse(names)
+	return strings.Join(names, " -> ")
+}
@@ -0,0 +1,200 @@
+package ecs
+
+import "testing"
+
+type schedulerMarker struct{}
+
+// funcSystem adapts a function to System
+type funcSystem func(w *World) error
+
+func (f funcSystem) Update(w *World) error { return f(w) }
+
+func TestSkippedSystemDropsTriggers(t *testing.T) {
+	w := NewWorld(nil)
+	allowed := true
+	var seen int
+	w.AddSystem(
+		funcSystem(func(*World) error { seen++; return nil }),
+		Name("react"),
+		RunIf(func(*World) bool { return allowed }),
+		RunOnEnter(NewQuery(With[schedulerMarker]())),
+	)
+
+	mark := func() {
+		t.Helper()
+		if err := Add(w, w.EntityManager.CreateEntity(), schedulerMarker{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	update := func() {
+		t.Helper()
+		if err := w.Update(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Skipped by its run condition
+	allowed = false
+	mark()
+	update()
+	allowed = true
+	update()
+	if seen != 0 {
+		t.Fatalf("system ran %d times for an entity that entered while its run condition failed", seen)
+	}
+
+	// Skipped while disabled
+	if err := w.DisableSystem("react"); err != nil {
+		t.Fatal(err)
+	}
+	mark()
+	update()
+	if err := w.EnableSystem("react"); err != nil {
+		t.Fatal(err)
+	}
+	update()
+	if seen != 0 {
+		t.Fatalf("system ran %d times for an entity that entered while it was disabled", seen)
+	}
+
+	mark()
+	update()
+	if seen != 1 {
+		t.Fatalf("system ran %d times for an entity that entered while it was enabled, want 1", seen)
+	}
+}
+
+type parallelA struct{ N int }
+type parallelB struct{ N int }
+type parallelSpawned struct{}
+
+type parallelEvent struct{ From string }
+
+func (parallelEvent) Type() EventType { return "parallel" }
+func (parallelEvent) Entity() Entity  { return NoEntity }
+func (e parallelEvent) Data() any     { return e }
+
+// writerSystem increments its component on every entity, queues an event
+// and spawns an entity through the command buffer
+type writerSystem[T any] struct {
+	name  string
+	query *Query1[T]
+	bump  func(*T)
+}
+
+func (s *writerSystem[T]) Update(w *World) error {
+	s.query.Each(w, func(_ Entity, c *T) { s.bump(c) })
+	w.QueueEvent(parallelEvent{From: s.name})
+	AddComponent(w.Commands(), w.Commands().CreateEntity(), parallelSpawned{})
+	return nil
+}
+
+// batchOf returns the batch holding the named system
+func batchOf(w *World, name string) batch {
+	for _, stage := range w.scheduler.stages {
+		for _, b := range stage {
+			for _, entry := range b {
+				if entry.name == name {
+					return b
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Run with -race to check that systems sharing a batch do not race
+func TestNonConflictingSystemsRunInOneBatch(t *testing.T) {
+	w := NewWorld(nil)
+	for i := range 100 {
+		e := w.EntityManager.CreateEntity()
+		Add(w, e, parallelA{i})
+		Add(w, e, parallelB{i})
+	}
+	w.AddSystem(&writerSystem[parallelA]{
+		name: "a", query: NewQuery1[parallelA](), bump: func(c *parallelA) { c.N++ },
+	}, Name("a"), Writes(ID[parallelA]()))
+	w.AddSystem(&writerSystem[parallelB]{
+		name: "b", query: NewQuery1[parallelB](), bump: func(c *parallelB) { c.N++ },
+	}, Name("b"), Writes(ID[parallelB]()))
+	if err := w.BuildSchedule(); err != nil {
+		t.Fatal(err)
+	}
+	if b := batchOf(w, "a"); len(b) != 2 {
+		t.Fatalf("systems with disjoint writes were not batched together, batch of a has %d systems", len(b))
+	}
+
+	events := map[string]int{}
+	Subscribe(w, func(e parallelEvent) { events[e.From]++ })
+	const updates = 20
+	for range updates {
+		if err := w.Update(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if events["a"] != updates || events["b"] != updates {
+		t.Errorf("handled events %v, want %d from each system", events, updates)
+	}
+	if n := len(NewQuery(With[parallelSpawned]()).Entities(w)); n != 2*updates {
+		t.Errorf("%d entities spawned through commands, want %d", n, 2*updates)
+	}
+	NewQuery2[parallelA, parallelB]().Each(w, func(e Entity, a *parallelA, b *parallelB) {
+		if a.N != b.N {
+			t.Fatalf("entity %v has A %d and B %d, want both bumped equally", e, a.N, b.N)
+		}
+	})
+}
+
+// busySystem iterates its component and burns some CPU, standing in for a
+// system with real work to do
+type busySystem[T any] struct{ query *Query1[T] }
+
+func (s *busySystem[T]) Update(w *World) error {
+	sum := 0.0
+	s.query.Each(w, func(Entity, *T) {})
+	for i := range 200000 {
+		sum += float64(i) * 1.0001
+	}
+	_ = sum
+	return nil
+}
+
+type busy0 struct{ V float64 }
+type busy1 struct{ V float64 }
+type busy2 struct{ V float64 }
+type busy3 struct{ V float64 }
+
+func addBusy[T any](w *World, name string, declared bool) {
+	opts := []SystemOption{Name(name)}
+	if declared {
+		opts = append(opts, Writes(ID[T]()))
+	}
+	w.AddSystem(&busySystem[T]{query: NewQuery1[T]()}, opts...)
+}
+
+// benchmarkSystems runs four busy systems, which run concurrently when
+// they declare their access
+func benchmarkSystems(b *testing.B, declared bool) {
+	w := NewWorld(nil)
+	for range 1000 {
+		e := w.EntityManager.CreateEntity()
+		Add(w, e, busy0{})
+		Add(w, e, busy1{})
+		Add(w, e, busy2{})
+		Add(w, e, busy3{})
+	}
+	addBusy[busy0](w, "busy0", declared)
+	addBusy[busy1](w, "busy1", declared)
+	addBusy[busy2](w, "busy2", declared)
+	addBusy[busy3](w, "busy3", declared)
+	b.ResetTimer()
+	for range b.N {
+		if err := w.Update(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSystemsSequential(b *testing.B) { benchmarkSystems(b, false) }
+func BenchmarkSystemsParallel(b *testing.B)   { benchmarkSystems(b, true) }
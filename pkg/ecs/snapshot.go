@@ -0,0 +1,240 @@
+package ecs
+
+import (
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Encoder writes a stream of values
+type Encoder interface {
+	Encode(v any) error
+}
+
+// Decoder reads back a stream of values written by the matching Encoder
+type Decoder interface {
+	Decode(v any) error
+}
+
+// Codec chooses the wire format of snapshots
+type Codec interface {
+	NewEncoder(w io.Writer) Encoder
+	NewDecoder(r io.Reader) Decoder
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) NewEncoder(w io.Writer) Encoder { return json.NewEncoder(w) }
+func (jsonCodec) NewDecoder(r io.Reader) Decoder { return json.NewDecoder(r) }
+
+type gobCodec struct{}
+
+func (gobCodec) NewEncoder(w io.Writer) Encoder { return gob.NewEncoder(w) }
+func (gobCodec) NewDecoder(r io.Reader) Decoder { return gob.NewDecoder(r) }
+
+var (
+	// JSONCodec writes snapshots as a stream of JSON values, one per line
+	JSONCodec Codec = jsonCodec{}
+	// GobCodec writes snapshots with encoding/gob
+	GobCodec Codec = gobCodec{}
+)
+
+const snapshotVersion = 1
+
+// snapshotHeader is written first, followed by every component value in the
+// order listed by Entities and then every resource value in the order listed
+// by Resources
+type snapshotHeader struct {
+	Version   int
+	Slots     []snapshotSlot
+	Free      []uint32
+	Next      uint32
+	Entities  []snapshotEntity
+	Resources []string
+}
+
+type snapshotSlot struct {
+	Generation uint32
+	Alive      bool
+}
+
+type snapshotEntity struct {
+	Entity     Entity
+	Name       string
+	Components []string
+}
+
+// Snapshot writes every entity, with its name and components, and every
+// resource in the world using codec. All component and resource types in use
+// must have been registered with RegisterComponent.
+// Systems, event handlers and queued events are not part of the snapshot,
+// nor are queued commands, so entities reserved by Commands.CreateEntity are
+// saved as free.
+func (w *World) Snapshot(codec Codec, out io.Writer) error {
+	em := w.EntityManager
+	header := snapshotHeader{
+		Version: snapshotVersion,
+		Slots:   make([]snapshotSlot, len(em.slots)),
+		Free:    slices.Clone(em.free),
+		Next:    uint32(len(em.slots)),
+	}
+	free := make([]bool, len(em.slots))
+	for _, index := range em.free {
+		free[index] = true
+	}
+	for i, slot := range em.slots {
+		header.Slots[i] = snapshotSlot{Generation: slot.generation, Alive: slot.alive}
+		// Slots neither alive nor free are reserved by pending commands
+		if i > 0 && !slot.alive && !free[i] {
+			header.Free = append(header.Free, uint32(i))
+		}
+	}
+
+	var values []any
+	for _, entity := range em.GetAllEntities() {
+		se := snapshotEntity{Entity: entity, Name: em.name(entity)}
+		arch, row := w.ComponentManager.lookup(entity)
+		if arch != nil {
+			for i, id := range arch.ids {
+				info := registry.info(id)
+				if info.name == "" {
+					return fmt.Errorf("ecs: component %v is not registered for snapshots", info.typ)
+				}
+				se.Components = append(se.Components, info.name)
+				values = append(values, arch.columns[i].get(row))
+			}
+		}
+		header.Entities = append(header.Entities, se)
+	}
+
+	// Resources are written in name order so snapshots are reproducible
+	resourceIDs := slices.SortedFunc(maps.Keys(w.resources), func(a, b ComponentID) int {
+		return strings.Compare(registry.info(a).name, registry.info(b).name)
+	})
+	for _, id := range resourceIDs {
+		info := registry.info(id)
+		if info.name == "" {
+			return fmt.Errorf("ecs: resource %v is not registered for snapshots", info.typ)
+		}
+		header.Resources = append(header.Resources, info.name)
+		values = append(values, w.resources[id].get())
+	}
+
+	enc := codec.NewEncoder(out)
+	if err := enc.Encode(header); err != nil {
+		return fmt.Errorf("ecs: encoding snapshot header: %w", err)
+	}
+	for _, value := range values {
+		if err := enc.Encode(value); err != nil {
+			return fmt.Errorf("ecs: encoding value: %w", err)
+		}
+	}
+	return nil
+}
+
+// Restore replaces every entity, component and resource in the world with the ones
+// read from a snapshot written by Snapshot with the same codec. Entity
+// handles are preserved, so components and relations referring to other
+// entities stay valid, and snapshots with relations to missing entities are
+// rejected. Restored components and resources count as added, without running
+// OnAdd hooks or notifying observers. Queued events and commands are
+// discarded. On error the world is left unchanged.
+func (w *World) Restore(codec Codec, in io.Reader) error {
+	dec := codec.NewDecoder(in)
+	var header snapshotHeader
+	if err := dec.Decode(&header); err != nil {
+		return fmt.Errorf("ecs: decoding snapshot header: %w", err)
+	}
+	if header.Version != snapshotVersion {
+		return fmt.Errorf("ecs: unsupported snapshot version %d", header.Version)
+	}
+
+	if len(header.Slots) == 0 || header.Slots[0].Alive {
+		return fmt.Errorf("ecs: snapshot has invalid entity slots")
+	}
+
+	em := NewEntityManager()
+	em.slots = make([]entitySlot, len(header.Slots))
+	for i, slot := range header.Slots {
+		em.slots[i] = entitySlot{generation: slot.Generation, alive: slot.Alive}
+		if slot.Alive {
+			em.count++
+		}
+	}
+	// Handing out an index that is in use would give two entities one handle
+	if int64(header.Next) < int64(len(header.Slots)) {
+		return fmt.Errorf("ecs: snapshot's next entity index %d is in use", header.Next)
+	}
+	freed := make(map[uint32]bool, len(header.Free))
+	for _, index := range header.Free {
+		if index == 0 || int(index) >= len(em.slots) || em.slots[index].alive || freed[index] {
+			return fmt.Errorf("ecs: snapshot's free list has invalid entity index %d", index)
+		}
+		freed[index] = true
+	}
+	em.free = header.Free
+	em.nextIndex = header.Next
+
+	// Decode into a scratch world so a bad snapshot leaves w untouched
+	restored := &World{
+		EntityManager:    em,
+		ComponentManager: NewComponentManager(em),
+		resources:        make(map[ComponentID]resource),
+	}
+	// Keep ticks moving forward so readers see the restored state as new
+	restored.ComponentManager.tick.Store(w.ComponentManager.tick.Load())
+	for _, se := range header.Entities {
+		if !em.IsAlive(se.Entity) {
+			return fmt.Errorf("ecs: snapshot lists entity %v that is not alive", se.Entity)
+		}
+		if err := em.setName(se.Entity, se.Name); err != nil {
+			return fmt.Errorf("ecs: restoring name of entity %v: %w", se.Entity, err)
+		}
+		for _, name := range se.Components {
+			info, exists := registry.lookupName(name)
+			if !exists {
+				return fmt.Errorf("ecs: snapshot has unregistered component %q", name)
+			}
+			if err := info.decode(dec, restored, se.Entity); err != nil {
+				return fmt.Errorf("ecs: decoding component %q of entity %v: %w", name, se.Entity, err)
+			}
+		}
+	}
+
+	for _, name := range header.Resources {
+		info, exists := registry.lookupName(name)
+		if !exists {
+			return fmt.Errorf("ecs: snapshot has unregistered resource %q", name)
+		}
+		if err := info.decodeResource(dec, restored); err != nil {
+			return fmt.Errorf("ecs: decoding resource %q: %w", name, err)
+		}
+	}
+
+	if err := restored.ComponentManager.checkRelations(); err != nil {
+		return err
+	}
+
+	restored.ComponentManager.hooks = w.ComponentManager.hooks
+	restored.ComponentManager.observers = w.ComponentManager.observers
+	w.EntityManager = em
+	w.ComponentManager = restored.ComponentManager
+	w.resources = restored.resources
+	w.commands = newCommands(em)
+	w.events.mu.Lock()
+	w.events.queue = nil
+	w.events.mu.Unlock()
+	return nil
+}
+
+func decodeComponent[T any](dec Decoder, w *World, entity Entity) error {
+	var component T
+	if err := dec.Decode(&component); err != nil {
+		return err
+	}
+	return Add(w, entity, component)
+}
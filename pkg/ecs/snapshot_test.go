@@ -0,0 +1,207 @@
+package ecs
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+type snapshotPos struct{ X, Y int }
+
+type snapshotMarked struct{}
+
+type snapshotGrid struct {
+	Rows  [][]int
+	Moves map[string]int
+}
+
+func init() {
+	RegisterComponent[snapshotPos]("snapshot_pos")
+	RegisterComponent[snapshotMarked]("snapshot_marked")
+	RegisterComponent[snapshotGrid]("snapshot_grid")
+}
+
+// snapshotJSON returns the header of a JSON snapshot and the values after it
+func snapshotJSON(t *testing.T, w *World) (snapshotHeader, []byte) {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := w.Snapshot(JSONCodec, &buf); err != nil {
+		t.Fatal(err)
+	}
+	dec := json.NewDecoder(&buf)
+	var header snapshotHeader
+	if err := dec.Decode(&header); err != nil {
+		t.Fatal(err)
+	}
+	// Whatever the decoder has not consumed yet is the values
+	var values bytes.Buffer
+	values.ReadFrom(dec.Buffered())
+	values.Write(buf.Bytes())
+	return header, values.Bytes()
+}
+
+func restoreJSON(w *World, header snapshotHeader, values []byte) error {
+	var buf bytes.Buffer
+	json.NewEncoder(&buf).Encode(header)
+	buf.Write(values)
+	return w.Restore(JSONCodec, &buf)
+}
+
+func TestRestoreRejectsReusedIndices(t *testing.T) {
+	newWorld := func() *World {
+		w := NewWorld(nil)
+		for i := range 3 {
+			e := w.EntityManager.CreateEntity()
+			if err := Add(w, e, snapshotPos{X: i}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		w.RemoveEntity(newEntity(2, 0))
+		return w
+	}
+
+	tests := []struct {
+		name string
+		edit func(h *snapshotHeader)
+	}{
+		{"next in use", func(h *snapshotHeader) { h.Next = 1 }},
+		{"free slot alive", func(h *snapshotHeader) { h.Free = append(h.Free, 1) }},
+		{"free slot out of range", func(h *snapshotHeader) { h.Free = append(h.Free, 9) }},
+		{"free slot listed twice", func(h *snapshotHeader) { h.Free = append(h.Free, 2) }},
+		{"free slot zero", func(h *snapshotHeader) { h.Free = append(h.Free, 0) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newWorld()
+			header, values := snapshotJSON(t, w)
+			tt.edit(&header)
+			if err := restoreJSON(w, header, values); err == nil {
+				t.Fatal("Restore accepted the snapshot")
+			}
+			if got := w.EntityManager.Count(); got != 2 {
+				t.Fatalf("world changed by a failed restore, %d entities", got)
+			}
+		})
+	}
+
+	w := newWorld()
+	header, values := snapshotJSON(t, w)
+	if err := restoreJSON(w, header, values); err != nil {
+		t.Fatal(err)
+	}
+	e := w.EntityManager.CreateEntity()
+	if e != newEntity(2, 1) {
+		t.Fatalf("CreateEntity after Restore returned %v, want the freed index 2v1", e)
+	}
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	w := NewWorld(nil)
+	board := w.EntityManager.CreateEntity()
+	removed := w.EntityManager.CreateEntity()
+	x := w.EntityManager.CreateEntity()
+	o := w.EntityManager.CreateEntity()
+	w.RemoveEntity(removed)
+	for _, err := range []error{
+		w.SetName(board, "board"),
+		w.SetName(x, "player_x"),
+		Add(w, x, snapshotPos{X: 1, Y: 2}),
+		Add(w, o, snapshotPos{X: 2, Y: 0}),
+		AddTag[snapshotMarked](w, x),
+		Relate[ChildOf](w, x, board),
+		Relate[ChildOf](w, o, board),
+	} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	SetResource(w, snapshotGrid{
+		Rows:  [][]int{{1, 0, 0}, {0, 2, 0}, {0, 0, 0}},
+		Moves: map[string]int{"x": 1, "o": 1},
+	})
+
+	for _, tt := range []struct {
+		name  string
+		codec Codec
+	}{
+		{"json", JSONCodec},
+		{"gob", GobCodec},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := w.Snapshot(tt.codec, &buf); err != nil {
+				t.Fatal(err)
+			}
+			restored := NewWorld(nil)
+			if err := restored.Restore(tt.codec, &buf); err != nil {
+				t.Fatal(err)
+			}
+
+			if got, want := restored.EntityManager.GetAllEntities(), w.EntityManager.GetAllEntities(); !slices.Equal(got, want) {
+				t.Errorf("restored entities %v, want %v", got, want)
+			}
+			for _, name := range []string{"board", "player_x"} {
+				got, _ := restored.Lookup(name)
+				if want, _ := w.Lookup(name); got != want {
+					t.Errorf("restored %q is %v, want %v", name, got, want)
+				}
+			}
+			for _, e := range []Entity{x, o} {
+				got, _ := Get[snapshotPos](restored, e)
+				want, _ := Get[snapshotPos](w, e)
+				if got == nil || *got != *want {
+					t.Errorf("restored position of %v is %v, want %v", e, got, *want)
+				}
+			}
+			if !HasTag[snapshotMarked](restored, x) || HasTag[snapshotMarked](restored, o) {
+				t.Error("tag not restored on the right entity")
+			}
+			if got := Children(restored, board); !slices.Equal(got, []Entity{x, o}) {
+				t.Errorf("restored children of the board %v, want [%v %v]", got, x, o)
+			}
+			got, err := Resource[snapshotGrid](restored)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want, _ := Resource[snapshotGrid](w); !reflect.DeepEqual(got, want) {
+				t.Errorf("restored resource %+v, want %+v", got, want)
+			}
+
+			// Both worlds hand out the same entities from here on
+			if got, want := restored.EntityManager.CreateEntity(), w.Clone().EntityManager.CreateEntity(); got != want {
+				t.Errorf("CreateEntity after Restore returned %v, want %v", got, want)
+			}
+			// Removing the parent still cascades
+			restored.RemoveEntity(board)
+			if restored.IsAlive(x) || restored.IsAlive(o) {
+				t.Error("children outlived their restored parent")
+			}
+		})
+	}
+}
+
+func TestSnapshotFreesReservedEntities(t *testing.T) {
+	w := NewWorld(nil)
+	reserved := w.Commands().CreateEntity()
+	created := w.EntityManager.CreateEntity()
+	if reserved.Index() != 1 || created.Index() != 2 {
+		t.Fatalf("got entities %v and %v, want indices 1 and 2", reserved, created)
+	}
+	pending := w.Commands().CreateEntity()
+
+	header, values := snapshotJSON(t, w)
+	if err := restoreJSON(w, header, values); err != nil {
+		t.Fatal(err)
+	}
+	var indices []uint32
+	for range 3 {
+		indices = append(indices, w.EntityManager.CreateEntity().Index())
+	}
+	slices.Sort(indices)
+	if want := []uint32{1, 3, 4}; !slices.Equal(indices, want) {
+		t.Errorf("CreateEntity after Restore handed out indices %v, want %v reused for the "+
+			"dropped reservations %v and %v", indices, want, reserved, pending)
+	}
+}
@@ -1,6 +1,86 @@
 package ecs
 
-// System processes entities with specific components
+import (
+	"errors"
+	"fmt"
+)
+
+// System processes entities with specific components. A returned error is
+// handled according to the world's ErrorPolicy.
 type System interface {
-	Update(world *World)
+	Update(world *World) error
+}
+
+// SystemError is an error returned by a system, tagged with the system's name
+type SystemError struct {
+	System string
+	Err    error
+}
+
+func (e *SystemError) Error() string {
+	return fmt.Sprintf("system %q: %v", e.System, e.Err)
+}
+
+func (e *SystemError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorPolicy decides what happens when a system returns an error
+type ErrorPolicy int
+
+const (
+	// LogErrors logs system errors and carries on. It is the default.
+	LogErrors ErrorPolicy = iota
+	// ReturnErrors runs every system and returns their errors from Update
+	ReturnErrors
+	// HaltOnError stops the world at the first system error. The rest of
+	// the update is skipped, and later updates do nothing until Resume is
+	// called. Update returns the error together with ErrWorldHalted.
+	HaltOnError
+)
+
+// ErrWorldHalted is returned by Update once a system error has halted the
+// world under the HaltOnError policy
+var ErrWorldHalted = errors.New("ecs: world halted")
+
+// SetErrorPolicy chooses how errors returned by systems are handled
+func (w *World) SetErrorPolicy(policy ErrorPolicy) {
+	w.errorPolicy = policy
+}
+
+// Resume lets a world halted by a system error run again. Commands and
+// events queued before it halted are processed by the next update.
+func (w *World) Resume() {
+	w.haltedBy = nil
+}
+
+// haltedError reports why the world is halted, or nil if it is not
+func (w *World) haltedError() error {
+	if w.haltedBy == nil {
+		return nil
+	}
+	return fmt.Errorf("%w: %w", ErrWorldHalted, w.haltedBy)
+}
+
+// handleErrors applies the error policy to the errors of an update
+func (w *World) handleErrors(errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	switch w.errorPolicy {
+	case ReturnErrors:
+		return errors.Join(errs...)
+	case HaltOnError:
+		w.haltedBy = errors.Join(errs...)
+		return w.haltedError()
+	}
+	for _, err := range errs {
+		var se *SystemError
+		if errors.As(err, &se) {
+			w.Logger.Error("system failed", "system", se.System, "error", se.Err)
+		} else {
+			w.Logger.Error("system failed", "error", err)
+		}
+	}
+	return nil
 }
@@ -0,0 +1,55 @@
+package ecs
+
+import "fmt"
+
+// Tags are components without data, declared as empty structs:
+//
+//	type CurrentTurn struct{}
+//
+// They are stored like any other component, so they work with Has, Remove,
+// queries, commands, hooks and snapshots, but being zero-size they take no
+// memory and adding them never allocates a value.
+
+// isTag reports whether the component type carries no data
+func (id ComponentID) isTag() bool {
+	return registry.info(id).typ.Size() == 0
+}
+
+// checkTag panics if T is not a tag
+func checkTag[T any]() ComponentID {
+	id := ID[T]()
+	if !id.isTag() {
+		panic(fmt.Sprintf("ecs: %v is not a tag, it has fields", registry.info(id).typ))
+	}
+	return id
+}
+
+// AddTag attaches the tag T to entity. It panics if T has fields.
+func AddTag[T any](w *World, entity Entity) error {
+	checkTag[T]()
+	var tag T
+	return Add(w, entity, tag)
+}
+
+// HasTag reports whether entity has the tag T
+func HasTag[T any](w *World, entity Entity) bool {
+	return w.ComponentManager.HasComponent(entity, checkTag[T]())
+}
+
+// RemoveTag detaches the tag T from entity, if present
+func RemoveTag[T any](w *World, entity Entity) {
+	w.ComponentManager.RemoveComponent(entity, checkTag[T]())
+}
+
+// MoveTag hands the tag T from one entity to another, for markers only one
+// entity holds at a time, such as whose turn it is
+func MoveTag[T any](w *World, from, to Entity) error {
+	if from == to {
+		return AddTag[T](w, to)
+	}
+	if err := AddTag[T](w, to); err != nil {
+		return err
+	}
+	RemoveTag[T](w, from)
+	return nil
+}
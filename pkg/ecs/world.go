@@ -1,73 +1,232 @@
 package ecs
 
-import "log"
+import (
+	"log/slog"
+	"slices"
+	"sync"
+)
 
 // World is the main ECS container that holds all entities, components, and systems
 type World struct {
 	EntityManager    *EntityManager
 	ComponentManager *ComponentManager
-	systems          []System
-	eventQueue       []EventInterface // Simple event queue for communication
-	eventHandlers    map[EventType][]func(EventInterface)
-	Logger           *log.Logger
+	scheduler        scheduler
+	commands         *Commands
+	resources        map[ComponentID]resource
+	observers        []*observer
+	events           eventBus
+	eventLog         *EventLog
+	updates          uint64 // number of completed calls to Update
+	updating         bool
+	dispatching      int // depth of event dispatches in progress
+	errorPolicy      ErrorPolicy
+	haltedBy         error // set when a system error halted the world
+	metrics          worldMetrics
+	Logger           *slog.Logger
 }
 
-func NewWorld(logger *log.Logger) *World {
+// NewWorld creates an empty world reporting problems to logger, or to
+// slog.Default if logger is nil
+func NewWorld(logger *slog.Logger) *World {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	entityManager := NewEntityManager()
 	return &World{
-		EntityManager:    NewEntityManager(),
-		ComponentManager: NewComponentManager(),
-		systems:          []System{},
-		eventQueue:       []EventInterface{},
-		eventHandlers:    make(map[EventType][]func(EventInterface)),
+		EntityManager:    entityManager,
+		ComponentManager: NewComponentManager(entityManager),
+		commands:         newCommands(entityManager),
+		resources:        make(map[ComponentID]resource),
+		events:           newEventBus(),
 		Logger:           logger,
 	}
 }
 
-func (w *World) AddSystem(system System) {
-	w.systems = append(w.systems, system)
+// AddSystem registers a system. The options control its stage, its ordering
+// relative to other systems and the conditions under which it runs.
+func (w *World) AddSystem(system System, opts ...SystemOption) {
+	entry := w.scheduler.add(system, opts...)
+	for _, t := range entry.triggers {
+		w.ComponentManager.observers = append(w.ComponentManager.observers, t.observer)
+	}
 }
 
-func (w *World) RemoveEntity(entity Entity) {
-	w.EntityManager.RemoveEntity(entity)
-	w.ComponentManager.RemoveAllComponents(entity)
+// GetSystem returns the system registered under name
+func (w *World) GetSystem(name string) (System, bool) {
+	entry, err := w.scheduler.find(name)
+	if err != nil {
+		return nil, false
+	}
+	return entry.system, true
 }
 
-func (w *World) Update() {
-	for _, system := range w.systems {
-		system.Update(w)
+// DisableSystem stops the named system from running until it is enabled
+// again. Systems ordered against it keep their place.
+func (w *World) DisableSystem(name string) error {
+	entry, err := w.scheduler.find(name)
+	if err != nil {
+		return err
 	}
+	entry.disabled = true
+	return nil
+}
 
-	// Process events after all systems have updated
-	w.processEvents()
+// EnableSystem lets a system disabled with DisableSystem run again
+func (w *World) EnableSystem(name string) error {
+	entry, err := w.scheduler.find(name)
+	if err != nil {
+		return err
+	}
+	entry.disabled = false
+	return nil
 }
 
-// Simple event system for communication between ECS and external systems
-type EventType string
+// RemoveSystem unregisters the named system. It fails if other systems are
+// ordered before or after it. When called during an update, the system may
+// still run in that update.
+func (w *World) RemoveSystem(name string) error {
+	entry, err := w.scheduler.remove(name)
+	if err != nil {
+		return err
+	}
+	w.ComponentManager.observers = slices.DeleteFunc(w.ComponentManager.observers, func(o *observer) bool {
+		return slices.ContainsFunc(entry.triggers, func(t trigger) bool { return t.observer == o })
+	})
+	return nil
+}
 
-type EventInterface interface {
-	Type() EventType
-	Entity() Entity
-	Data() any
+// Commands returns the world's buffer for deferred structural changes.
+// Systems should use it instead of adding or removing entities and
+// components directly while they iterate.
+func (w *World) Commands() *Commands {
+	return w.commands
 }
 
-func (w *World) RegisterEventHandler(eventType EventType, handler func(EventInterface)) {
-	w.eventHandlers[eventType] = append(w.eventHandlers[eventType], handler)
+// BuildSchedule validates the system ordering constraints and fixes the run
+// order, reporting unknown references and cycles. Update builds the schedule
+// on demand, but calling this at startup surfaces mistakes early.
+func (w *World) BuildSchedule() error {
+	return w.scheduler.build()
 }
 
-func (w *World) QueueEvent(event EventInterface) {
-	w.eventQueue = append(w.eventQueue, event)
+// RemoveEntity removes entity with all of its components and the relations
+// other entities have to it. Entities related to it by a relation kind
+// registered with Cascade, such as its children, are removed as well.
+func (w *World) RemoveEntity(entity Entity) {
+	if !w.IsAlive(entity) {
+		return
+	}
+	w.ComponentManager.RemoveAllComponents(entity)
+	w.EntityManager.remove(entity)
+	w.removeRelationsTo(entity)
+}
+
+// IsAlive reports whether entity exists in the world
+func (w *World) IsAlive(entity Entity) bool {
+	return w.EntityManager.IsAlive(entity)
+}
+
+// structureVersion changes whenever entities or components are added or removed
+func (w *World) structureVersion() uint64 {
+	return w.EntityManager.version + w.ComponentManager.version
 }
 
-func (w *World) processEvents() {
-	// Process all events in the queue
-	for _, event := range w.eventQueue {
-		if handlers, exists := w.eventHandlers[event.Type()]; exists {
-			for _, handler := range handlers {
-				handler(event)
+// Update runs every system once, stage by stage, then processes the queued
+// events. Errors returned by systems are handled according to the error
+// policy, see SetErrorPolicy. It also fails if the schedule is invalid or
+// the world is halted.
+func (w *World) Update() error {
+	if err := w.haltedError(); err != nil {
+		return err
+	}
+	if w.scheduler.dirty {
+		if err := w.scheduler.build(); err != nil {
+			return err
+		}
+	}
+
+	w.updating = true
+	start := w.beginUpdate()
+	defer func() {
+		w.endUpdate(start)
+		w.updating = false
+		w.updates++
+	}()
+
+	// Catch up on changes made since the previous update
+	w.runObservers()
+
+	var errs []error
+	for _, stage := range w.scheduler.stages {
+		for _, b := range stage {
+			errs = append(errs, w.runBatch(b)...)
+			if len(errs) > 0 && w.errorPolicy == HaltOnError {
+				return w.handleErrors(errs)
 			}
 		}
+		// Structural changes queued during the stage become visible to the next one
+		w.runObservers()
+	}
+
+	// Process events after all systems have updated
+	w.processEvents()
+	w.runObservers()
+	w.ComponentManager.rotateRemoved()
+	return w.handleErrors(errs)
+}
+
+func (w *World) applyCommands() {
+	for _, err := range w.commands.apply(w) {
+		w.Logger.Error("command failed", "error", err)
+	}
+}
+
+// runBatch runs the systems of a batch whose run conditions hold, on
+// separate goroutines when there is more than one, and returns their errors
+func (w *World) runBatch(b batch) []error {
+	running := make([]*systemEntry, 0, len(b))
+	for _, entry := range b {
+		if entry.shouldRun(w) {
+			running = append(running, entry)
+		}
 	}
 
-	// Clear queue
-	w.eventQueue = w.eventQueue[:0]
+	if len(running) == 1 {
+		if err := running[0].run(w); err != nil {
+			return []error{err}
+		}
+		return nil
+	}
+	if len(running) == 0 {
+		return nil
+	}
+
+	// Concurrent systems only read and write existing components, so the
+	// storage layout is frozen until they are all done
+	w.setFrozen(true)
+	defer w.setFrozen(false)
+
+	results := make([]error, len(running))
+	var wg sync.WaitGroup
+	for i, entry := range running {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = entry.run(w)
+		}()
+	}
+	wg.Wait()
+
+	var errs []error
+	for _, err := range results {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+func (w *World) setFrozen(frozen bool) {
+	w.EntityManager.frozen = frozen
+	w.ComponentManager.frozen = frozen
 }